@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ms-henglu/go-msgraph-types/docgen"
+	"github.com/ms-henglu/go-msgraph-types/types"
+)
+
+func runDocs(args []string) error {
+	flags := flag.NewFlagSet("docs", flag.ExitOnError)
+	apiVersion := flags.String("api-version", "v1.0", "the api version of the resources")
+	output := flags.String("output", "docs", "the directory of the generated pages")
+	var urls []string
+	flags.Var((*stringsFlag)(&urls), "resource", "the resource URL to document, e.g. `/applications`, it can be specified multiple times")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	urls = append(urls, flags.Args()...)
+	if len(urls) == 0 {
+		return fmt.Errorf("at least one -resource is required")
+	}
+
+	pages, err := docgen.Generate(types.DefaultMSGraphSchemaLoader(), *apiVersion, urls)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*output, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %+v", err)
+	}
+	for _, page := range pages {
+		if err := os.WriteFile(filepath.Join(*output, page.FileName), []byte(page.Content), 0644); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filepath.Join(*output, "index.md"), []byte(docgen.Index(*apiVersion, pages)), 0644)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const usage = `Usage: msgraph-types <command> [options]
+
+Commands:
+  docs         writes the markdown reference pages of the resources
+  prune        writes a minimal OpenAPI document which only contains the selected resources
+  tree         prints the resource definitions as indented trees
+  typescript   writes the TypeScript declarations of the request bodies of the resources
+
+Run 'msgraph-types <command> -h' for the options of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "docs":
+		err = runDocs(os.Args[2:])
+	case "prune":
+		err = runPrune(os.Args[2:])
+	case "tree":
+		err = runTree(os.Args[2:])
+	case "typescript":
+		err = runTypeScript(os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %+v\n", err)
+		os.Exit(1)
+	}
+}
+
+// stringsFlag is a flag which can be specified multiple times, or with comma separated values.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*f = append(*f, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	typesEmbed "github.com/ms-henglu/go-msgraph-types/embed"
+	"github.com/ms-henglu/go-msgraph-types/prune"
+)
+
+func runPrune(args []string) error {
+	flags := flag.NewFlagSet("prune", flag.ExitOnError)
+	apiVersion := flags.String("api-version", "v1.0", "the api version of the embedded document to prune")
+	input := flags.String("input", "", "the path of the document to prune, the embedded document is used if it's not specified")
+	output := flags.String("output", "", "the path of the pruned document, e.g. `openapi/v1.0/openapi.yaml`, it's written to stdout if it's not specified")
+	options := prune.Options{}
+	flags.Var((*stringsFlag)(&options.Resources), "resource", "the resource URL to keep, e.g. `/applications`, it can be specified multiple times")
+	flags.Var((*stringsFlag)(&options.Prefixes), "prefix", "the path prefix to keep, it can be specified multiple times")
+	flags.Var((*stringsFlag)(&options.Tags), "tag", "the operation tag prefix to keep, it can be specified multiple times")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(options.Resources) == 0 && len(options.Prefixes) == 0 && len(options.Tags) == 0 {
+		return fmt.Errorf("at least one of -resource, -prefix and -tag is required")
+	}
+
+	var data []byte
+	var err error
+	if *input != "" {
+		data, err = os.ReadFile(*input)
+	} else {
+		data, err = fs.ReadFile(typesEmbed.StaticFiles, fmt.Sprintf("openapi/%s/openapi.yaml", *apiVersion))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read document: %+v", err)
+	}
+
+	out, err := prune.Prune(data, options)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(*output), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %+v", err)
+	}
+	return os.WriteFile(*output, out, 0644)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ms-henglu/go-msgraph-types/types"
+)
+
+func runTree(args []string) error {
+	flags := flag.NewFlagSet("tree", flag.ExitOnError)
+	apiVersion := flags.String("api-version", "v1.0", "the api version of the resources")
+	depth := flags.Int("depth", 0, "the depth of the nested properties to print, it's unlimited if it's not specified")
+	descriptionLength := flags.Int("description-length", 0, "the max length of the descriptions, the descriptions are omitted if it's negative")
+	expandNavigation := flags.Bool("expand-navigation", false, "expand the entity types of the navigation properties, use it with -depth for the large entity types")
+	var urls []string
+	flags.Var((*stringsFlag)(&urls), "resource", "the resource URL to print, e.g. `/applications`, it can be specified multiple times")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	urls = append(urls, flags.Args()...)
+	if len(urls) == 0 {
+		return fmt.Errorf("at least one -resource is required")
+	}
+
+	loader := types.DefaultMSGraphSchemaLoader()
+	options := &types.RenderOptions{
+		MaxDepth:          *depth,
+		DescriptionLength: *descriptionLength,
+		ExpandNavigation:  *expandNavigation,
+	}
+	for i, url := range urls {
+		resource := loader.GetResourceDefinition(*apiVersion, url)
+		if resource == nil {
+			return fmt.Errorf("resource %s is not found in api-version %s", url, *apiVersion)
+		}
+		if i != 0 {
+			fmt.Fprintln(os.Stdout)
+		}
+		fmt.Fprint(os.Stdout, types.Render(resource, options))
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ms-henglu/go-msgraph-types/tsgen"
+	"github.com/ms-henglu/go-msgraph-types/types"
+)
+
+func runTypeScript(args []string) error {
+	flags := flag.NewFlagSet("typescript", flag.ExitOnError)
+	apiVersion := flags.String("api-version", "v1.0", "the api version of the resources")
+	output := flags.String("output", "", "the path of the declarations, e.g. `msgraph.d.ts`, it's written to stdout if it's not specified")
+	var urls []string
+	flags.Var((*stringsFlag)(&urls), "resource", "the resource URL whose request body is declared, e.g. `/applications`, it can be specified multiple times")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	urls = append(urls, flags.Args()...)
+	if len(urls) == 0 {
+		return fmt.Errorf("at least one -resource is required")
+	}
+
+	out, err := tsgen.Generate(types.DefaultMSGraphSchemaLoader(), *apiVersion, urls)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = fmt.Fprint(os.Stdout, out)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(*output), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %+v", err)
+	}
+	return os.WriteFile(*output, []byte(out), 0644)
+}
@@ -0,0 +1,310 @@
+package docgen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ms-henglu/go-msgraph-types/types"
+)
+
+// Page is a markdown page of a resource.
+type Page struct {
+	Url string
+	// FileName is the name of the page, e.g. `applications_application-id_extensionProperties.md`.
+	FileName string
+	Content  string
+}
+
+// Generate returns the markdown pages of the resources, the pages are in the same order as the urls.
+func Generate(loader *types.MSGraphSchemaLoader, apiVersion string, urls []string) ([]Page, error) {
+	out := make([]Page, 0, len(urls))
+	for _, url := range urls {
+		resource := loader.GetResourceDefinition(apiVersion, url)
+		if resource == nil {
+			return nil, fmt.Errorf("resource %s is not found in api-version %s", url, apiVersion)
+		}
+		out = append(out, Page{
+			Url:      url,
+			FileName: FileName(url),
+			Content:  RenderResource(resource, apiVersion),
+		})
+	}
+	return out, nil
+}
+
+// Index returns the markdown page which links to the pages.
+func Index(apiVersion string, pages []Page) string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "# Microsoft Graph resources (%s)\n\n", apiVersion)
+	for _, page := range pages {
+		fmt.Fprintf(builder, "- [%s](%s)\n", page.Url, page.FileName)
+	}
+	return builder.String()
+}
+
+var fileNameRegex = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// FileName returns the name of the page of the url, e.g. `applications_application-id_extensionProperties.md`.
+func FileName(url string) string {
+	name := strings.Trim(fileNameRegex.ReplaceAllString(strings.ReplaceAll(url, "/", "_"), ""), "_")
+	if name == "" {
+		name = "root"
+	}
+	return name + ".md"
+}
+
+// RenderResource returns the markdown page of the resource, it contains the description, the link to the external
+// documentation, the tables of the arguments and the read-only attributes, and a section for each nested block.
+func RenderResource(resource *types.ResourceType, apiVersion string) string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "# `%s`\n\n", resource.Url)
+	if resource.Deprecated != nil {
+		fmt.Fprintf(builder, "> **Deprecated**%s\n\n", deprecationText(resource.Deprecated))
+	}
+	if resource.Name != "" {
+		fmt.Fprintf(builder, "%s\n\n", resource.Name)
+	}
+	if description := strings.TrimSpace(resource.Description); description != "" && description != resource.Name {
+		fmt.Fprintf(builder, "%s\n\n", description)
+	}
+
+	fmt.Fprintf(builder, "- API version: `%s`\n", apiVersion)
+	if resource.Kind != "" {
+		fmt.Fprintf(builder, "- Kind: %s\n", resource.Kind)
+	}
+	if resource.EntityType != "" {
+		fmt.Fprintf(builder, "- Entity type: `%s`\n", resource.EntityType)
+	}
+	if resource.ExternalDocs != nil && resource.ExternalDocs.Url != "" {
+		text := resource.ExternalDocs.Description
+		if text == "" {
+			text = resource.ExternalDocs.Url
+		}
+		fmt.Fprintf(builder, "- Reference: [%s](%s)\n", text, resource.ExternalDocs.Url)
+	}
+	builder.WriteString("\n")
+
+	var body types.TypeBase
+	if resource.Body != nil {
+		body = resource.Body.Type
+	}
+	objectType, ok := body.(*types.ObjectType)
+	if !ok {
+		return builder.String()
+	}
+
+	r := &renderer{
+		builder: builder,
+		anchors: make(map[*types.ObjectType]string),
+	}
+	r.anchors[objectType] = ""
+	r.properties("", objectType, "##")
+	for len(r.blocks) != 0 {
+		block := r.blocks[0]
+		r.blocks = r.blocks[1:]
+		fmt.Fprintf(builder, "<a id=\"%s\"></a>\n### `%s`\n\n", r.anchors[block.objectType], block.path)
+		if block.objectType.Name != "" {
+			fmt.Fprintf(builder, "Type: `%s`\n\n", block.objectType.Name)
+		}
+		r.properties(block.path, block.objectType, "####")
+	}
+	return builder.String()
+}
+
+type renderer struct {
+	builder *strings.Builder
+	// anchors are the anchors of the sections of the blocks, each object type has one section
+	anchors map[*types.ObjectType]string
+	// blocks are the nested blocks whose sections aren't rendered yet
+	blocks []block
+}
+
+type block struct {
+	path       string
+	objectType *types.ObjectType
+}
+
+// properties writes the tables of the arguments and the read-only attributes of the object.
+func (r *renderer) properties(path string, objectType *types.ObjectType, heading string) {
+	names := make([]string, 0, len(objectType.Properties))
+	for name := range objectType.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	arguments := make([]string, 0)
+	attributes := make([]string, 0)
+	for _, name := range names {
+		property := objectType.Properties[name]
+		if property.IsReadOnly() {
+			attributes = append(attributes, name)
+		} else {
+			arguments = append(arguments, name)
+		}
+	}
+
+	if len(arguments) != 0 {
+		fmt.Fprintf(r.builder, "%s Arguments\n\n", heading)
+		r.builder.WriteString("| Name | Type | Required | Description |\n")
+		r.builder.WriteString("| --- | --- | --- | --- |\n")
+		for _, name := range arguments {
+			property := objectType.Properties[name]
+			required := "Optional"
+			if property.IsRequired() {
+				required = "Required"
+			}
+			fmt.Fprintf(r.builder, "| `%s` | %s | %s | %s |\n", name, r.typeName(joinPath(path, name), propertyType(property)), required, propertyDescription(property))
+		}
+		r.builder.WriteString("\n")
+	}
+	if objectType.AdditionalProperties != nil {
+		fmt.Fprintf(r.builder, "Additional properties of type %s are allowed.\n\n", r.typeName(joinPath(path, "*"), objectType.AdditionalProperties.Type))
+	}
+	if len(attributes) != 0 {
+		fmt.Fprintf(r.builder, "%s Read-only attributes\n\n", heading)
+		r.builder.WriteString("| Name | Type | Description |\n")
+		r.builder.WriteString("| --- | --- | --- |\n")
+		for _, name := range attributes {
+			property := objectType.Properties[name]
+			fmt.Fprintf(r.builder, "| `%s` | %s | %s |\n", name, r.typeName(joinPath(path, name), propertyType(property)), propertyDescription(property))
+		}
+		r.builder.WriteString("\n")
+	}
+}
+
+// typeName returns the type in the tables, the nested objects are linked to their sections.
+func (r *renderer) typeName(path string, t types.TypeBase) string {
+	switch v := t.(type) {
+	case *types.ObjectType:
+		if len(v.Properties) == 0 && v.AdditionalProperties == nil {
+			return "object"
+		}
+		anchor, ok := r.anchors[v]
+		if !ok {
+			anchor = "block-" + strings.ToLower(fileNameRegex.ReplaceAllString(strings.ReplaceAll(path, ".", "-"), ""))
+			r.anchors[v] = anchor
+			r.blocks = append(r.blocks, block{path: path, objectType: v})
+		}
+		if anchor == "" {
+			return "object (recursive)"
+		}
+		return fmt.Sprintf("[object](#%s)", anchor)
+	case *types.ArrayType:
+		if v.ItemType == nil {
+			return "list"
+		}
+		return "list of " + r.typeName(path, v.ItemType.Type)
+	case *types.UnionType:
+		elements := make([]string, 0, len(v.Elements))
+		for i, element := range v.Elements {
+			if element != nil {
+				elements = append(elements, r.typeName(fmt.Sprintf("%s.%d", path, i), element.Type))
+			}
+		}
+		return strings.Join(elements, " or ")
+	case *types.StringType:
+		if v.Format != "" {
+			return fmt.Sprintf("string (%s)", v.Format)
+		}
+		return "string"
+	case *types.NumberType:
+		if v.Format != "" {
+			return fmt.Sprintf("number (%s)", v.Format)
+		}
+		return "number"
+	case *types.BooleanType:
+		return "boolean"
+	}
+	return "any"
+}
+
+func propertyType(property types.ObjectProperty) types.TypeBase {
+	if property.Type == nil {
+		return nil
+	}
+	return property.Type.Type
+}
+
+// propertyDescription returns the description of the property in the tables, followed by the notes of the flags, the
+// enum values and the deprecation.
+func propertyDescription(property types.ObjectProperty) string {
+	parts := make([]string, 0)
+	if property.Description != nil {
+		if description := strings.Join(strings.Fields(*property.Description), " "); description != "" {
+			parts = append(parts, description)
+		}
+	}
+	notes := make([]string, 0)
+	if property.IsDeployTimeConstant() {
+		notes = append(notes, "Can only be set on create.")
+	}
+	if property.IsWriteOnly() {
+		notes = append(notes, "Write-only, it's not returned in the responses.")
+	}
+	if property.IsNavigation() {
+		notes = append(notes, "Navigation property.")
+	}
+	if isSensitive(propertyType(property)) {
+		notes = append(notes, "Sensitive.")
+	}
+	parts = append(parts, notes...)
+	if enum := enumValues(propertyType(property)); len(enum) != 0 {
+		values := make([]string, 0, len(enum))
+		for _, value := range enum {
+			values = append(values, fmt.Sprintf("`%s`", value))
+		}
+		parts = append(parts, "Possible values: "+strings.Join(values, ", ")+".")
+	}
+	if property.Deprecated != nil {
+		parts = append(parts, fmt.Sprintf("**Deprecated**%s", deprecationText(property.Deprecated)))
+	}
+	return escapeTableCell(strings.Join(parts, " "))
+}
+
+func deprecationText(deprecation *types.Deprecation) string {
+	out := ""
+	if deprecation.RemovalDate != "" {
+		out += fmt.Sprintf(", it will be removed on %s", deprecation.RemovalDate)
+	}
+	if deprecation.Description != "" {
+		out += ": " + deprecation.Description
+	}
+	return out
+}
+
+func enumValues(t types.TypeBase) []string {
+	switch v := t.(type) {
+	case *types.StringType:
+		return v.Enum
+	case *types.ArrayType:
+		if v.ItemType != nil {
+			return enumValues(v.ItemType.Type)
+		}
+	}
+	return nil
+}
+
+func isSensitive(t types.TypeBase) bool {
+	switch v := t.(type) {
+	case *types.StringType:
+		return v.Sensitive
+	case *types.ObjectType:
+		return v.Sensitive
+	case *types.ArrayType:
+		return v.ItemType != nil && isSensitive(v.ItemType.Type)
+	}
+	return false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func escapeTableCell(input string) string {
+	return strings.ReplaceAll(input, "|", "\\|")
+}
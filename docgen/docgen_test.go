@@ -0,0 +1,49 @@
+package docgen
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ms-henglu/go-msgraph-types/types"
+)
+
+func Test_Generate(t *testing.T) {
+	loader := types.NewMSGraphSchemaLoader(os.DirFS("../types/testdata"))
+	pages, err := Generate(loader, "v1.0", []string{"/widgets", "/widgets/{widget-id}/parts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 || pages[1].FileName != "widgets_widget-id_parts.md" {
+		t.Fatalf("expect 2 pages but got %v", pages)
+	}
+
+	content := pages[0].Content
+	for _, expected := range []string{
+		"# `/widgets`\n",
+		"- Entity type: `microsoft.graph.widget`\n",
+		"- Reference: [Find more info here](https://example.com/widget-post)\n",
+		"## Arguments\n",
+		"| `displayName` | string | Required | The display name of the widget. |\n",
+		"| `color` | string | Optional | Possible values: `red`, `green`, `yellow`, `unknownFutureValue`, `blue`. |\n",
+		"| `legacyName` | string | Optional | Can only be set on create. **Deprecated**, it will be removed on 2025-01-01: Use displayName instead. |\n",
+		"| `password` | string (password) | Optional | Can only be set on create. Sensitive. |\n",
+		"| `settings` | [object](#block-settings) | Optional |  |\n",
+		"## Read-only attributes\n",
+		"| `id` | string | The unique identifier for an entity. Read-only. |\n",
+		"<a id=\"block-settings\"></a>\n### `settings`\n\nType: `widgetSettings`\n",
+		"| `mode` | string | Required | Possible values: `manual`, `automatic`. |\n",
+		"<a id=\"block-labels\"></a>\n### `labels`\n\nAdditional properties of type string are allowed.\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expect the page to contain %q but got:\n%s", expected, content)
+		}
+	}
+	if index := Index("v1.0", pages); !strings.Contains(index, "- [/widgets](widgets.md)\n") {
+		t.Errorf("expect the index to link to the pages but got:\n%s", index)
+	}
+
+	if _, err := Generate(loader, "v1.0", []string{"/notExist"}); err == nil {
+		t.Errorf("expect an error for the resource which doesn't exist")
+	}
+}
@@ -2,7 +2,10 @@ module github.com/ms-henglu/go-msgraph-types
 
 go 1.22.0
 
-require github.com/getkin/kin-openapi v0.128.0
+require (
+	github.com/getkin/kin-openapi v0.128.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/go-openapi/jsonpointer v0.21.0 // indirect
@@ -12,5 +15,4 @@ require (
 	github.com/mailru/easyjson v0.7.7 // indirect
 	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
 	github.com/perimeterx/marshmallow v1.1.5 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
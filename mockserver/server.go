@@ -0,0 +1,544 @@
+package mockserver
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ms-henglu/go-msgraph-types/types"
+)
+
+// Server is an in-memory Microsoft Graph emulator, it serves the resources of an api version discovered by the loader.
+// It implements http.Handler, so it can be used with httptest.NewServer.
+//
+// The supported requests are:
+// 1. POST, GET on the collections, e.g. `/applications`, `/applications/{application-id}/extensionProperties`.
+// 2. GET, PATCH, DELETE on the entities, e.g. `/applications/{application-id}`.
+// 3. POST, GET on the references, e.g. `/applications/{application-id}/owners/$ref`, and DELETE on the reference,
+// e.g. `/applications/{application-id}/owners/{directoryObject-id}/$ref`.
+//
+// Only the urls returned by ListResources of the loader are routed, the entities are addressed by appending the id to
+// a collection url. The other paths, e.g. the singletons like `/me`, the functions and the entities whose collections
+// aren't listed, return 404.
+//
+// The request bodies are validated by the resource definitions, and the errors are returned in the OData format.
+// The create-only properties are rejected by PATCH. The paths can be prefixed by the api version, e.g.
+// `/v1.0/applications`.
+type Server struct {
+	loader     *types.MSGraphSchemaLoader
+	apiVersion string
+	// templates are the urls of the collections and the references, e.g. `/applications`
+	templates []string
+
+	mutex sync.Mutex
+	// collections are keyed by the paths of the collections, e.g. `/applications/1234/extensionProperties`
+	collections map[string]*collection
+}
+
+// collection contains the entities of a collection, or the references of a reference collection.
+type collection struct {
+	ids      []string
+	entities map[string]map[string]interface{}
+}
+
+// DefaultPageSize is the number of entities returned in a page when `$top` is not specified.
+var DefaultPageSize = 100
+
+func NewServer(loader *types.MSGraphSchemaLoader, apiVersion string) *Server {
+	out := &Server{
+		loader:      loader,
+		apiVersion:  apiVersion,
+		collections: make(map[string]*collection),
+	}
+	for _, resource := range loader.ListResources(apiVersion) {
+		out.templates = append(out.templates, resource.Url)
+	}
+	return out
+}
+
+// Reset removes all entities.
+func (s *Server) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.collections = make(map[string]*collection)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) != 0 && segments[0] == s.apiVersion {
+		segments = segments[1:]
+	}
+	if len(segments) == 0 || segments[0] == "" {
+		writeError(w, http.StatusNotFound, "ResourceNotFound", "Resource not found for the empty path.")
+		return
+	}
+
+	route := s.route(segments)
+	if route == nil {
+		writeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("Resource not found for the segment '%s'.", segments[len(segments)-1]))
+		return
+	}
+
+	switch {
+	case route.kind == routeCollection && r.Method == http.MethodPost:
+		s.create(w, r, route)
+	case route.kind == routeCollection && r.Method == http.MethodGet:
+		s.list(w, r, route)
+	case route.kind == routeEntity && r.Method == http.MethodGet:
+		s.get(w, r, route)
+	case route.kind == routeEntity && r.Method == http.MethodPatch:
+		s.update(w, r, route)
+	case route.kind == routeEntity && r.Method == http.MethodDelete:
+		s.delete(w, route)
+	case route.kind == routeReferences && r.Method == http.MethodPost:
+		s.addReference(w, r, route)
+	case route.kind == routeReferences && r.Method == http.MethodGet:
+		s.list(w, r, route)
+	case route.kind == routeReference && r.Method == http.MethodDelete:
+		s.delete(w, route)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Request_BadRequest", fmt.Sprintf("The method '%s' is not allowed on the resource.", r.Method))
+	}
+}
+
+type routeKind int
+
+const (
+	routeCollection routeKind = iota
+	routeEntity
+	routeReferences
+	routeReference
+)
+
+type route struct {
+	kind routeKind
+	// template is the url of the collection or the reference collection, e.g. `/applications`
+	template string
+	// collection is the path of the collection, e.g. `/applications/1234/extensionProperties`
+	collection string
+	// id is the id of the entity or the reference
+	id string
+	// parent is the route of the entity which contains the collection, it's nil for the top level collections
+	parent *route
+}
+
+func (s *Server) route(segments []string) *route {
+	for _, template := range s.templates {
+		templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+		isReference := templateSegments[len(templateSegments)-1] == "$ref"
+		switch {
+		case matchSegments(templateSegments, segments):
+			kind := routeCollection
+			if isReference {
+				kind = routeReferences
+			}
+			return s.newRoute(kind, template, templateSegments, segments, "")
+		case !isReference && len(segments) == len(templateSegments)+1 && matchSegments(templateSegments, segments[:len(templateSegments)]):
+			return s.newRoute(routeEntity, template, templateSegments, segments[:len(templateSegments)], segments[len(templateSegments)])
+		case isReference && len(segments) == len(templateSegments)+1 && segments[len(segments)-1] == "$ref" &&
+			matchSegments(templateSegments[:len(templateSegments)-1], segments[:len(templateSegments)-1]):
+			return s.newRoute(routeReference, template, templateSegments, append(append([]string{}, segments[:len(templateSegments)-1]...), "$ref"), segments[len(segments)-2])
+		}
+	}
+	return nil
+}
+
+func (s *Server) newRoute(kind routeKind, template string, templateSegments []string, segments []string, id string) *route {
+	out := &route{
+		kind:       kind,
+		template:   template,
+		collection: "/" + strings.Join(segments, "/"),
+		id:         id,
+	}
+	// the parent is the entity addressed by the last variable of the template
+	for i := len(templateSegments) - 1; i >= 0; i-- {
+		if isVariableSegment(templateSegments[i]) {
+			out.parent = s.route(segments[:i+1])
+			break
+		}
+	}
+	return out
+}
+
+func matchSegments(templateSegments []string, segments []string) bool {
+	if len(templateSegments) != len(segments) {
+		return false
+	}
+	for i, templateSegment := range templateSegments {
+		if isVariableSegment(templateSegment) {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if !strings.EqualFold(templateSegment, segments[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isVariableSegment(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request, route *route) {
+	definition := s.loader.GetResourceDefinition(s.apiVersion, route.template)
+	if definition == nil {
+		writeError(w, http.StatusMethodNotAllowed, "Request_BadRequest", "The resource can't be created.")
+		return
+	}
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	if errs := definition.Validate(body, ""); len(errs) != 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.exists(route.parent) {
+		writeNotFound(w, route.parent)
+		return
+	}
+
+	entity, ok := definition.ApplyDefaults(body).(map[string]interface{})
+	if !ok {
+		entity = body
+	}
+	id := newID()
+	entity["id"] = id
+	assignReadOnlyFields(definition, entity)
+
+	c := s.collection(route.collection)
+	c.ids = append(c.ids, id)
+	c.entities[id] = entity
+	writeJSON(w, http.StatusCreated, entity)
+}
+
+func (s *Server) addReference(w http.ResponseWriter, r *http.Request, route *route) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	if definition := s.loader.GetResourceDefinition(s.apiVersion, route.template); definition != nil {
+		if errs := definition.Validate(body, ""); len(errs) != 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+	}
+	odataId, _ := body["@odata.id"].(string)
+	if odataId == "" {
+		writeError(w, http.StatusBadRequest, "Request_BadRequest", "The property '@odata.id' is required.")
+		return
+	}
+	id := odataId[strings.LastIndex(odataId, "/")+1:]
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.exists(route.parent) {
+		writeNotFound(w, route.parent)
+		return
+	}
+	c := s.collection(route.collection)
+	if _, ok := c.entities[id]; ok {
+		writeError(w, http.StatusBadRequest, "Request_BadRequest", "One or more added object references already exist for the following modified properties.")
+		return
+	}
+	c.ids = append(c.ids, id)
+	c.entities[id] = map[string]interface{}{"@odata.id": odataId}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, route *route) {
+	query := r.URL.Query()
+	top := DefaultPageSize
+	if value := query.Get("$top"); value != "" {
+		// `$top=0` is rejected, otherwise the next links never reach the end of the collection
+		v, err := strconv.Atoi(value)
+		if err != nil || v <= 0 {
+			writeError(w, http.StatusBadRequest, "BadRequest", fmt.Sprintf("Invalid value '%s' for $top query option found.", value))
+			return
+		}
+		top = v
+	}
+	skip := 0
+	if value := query.Get("$skiptoken"); value != "" {
+		v, err := strconv.Atoi(value)
+		if err != nil || v < 0 {
+			writeError(w, http.StatusBadRequest, "BadRequest", fmt.Sprintf("Invalid value '%s' for $skiptoken query option found.", value))
+			return
+		}
+		skip = v
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.exists(route.parent) {
+		writeNotFound(w, route.parent)
+		return
+	}
+
+	values := make([]interface{}, 0)
+	ids := make([]string, 0)
+	if c := s.collections[route.collection]; c != nil {
+		ids = c.ids
+	}
+	end := len(ids)
+	if skip > end {
+		skip = end
+	}
+	if skip+top < end {
+		end = skip + top
+	}
+	for _, id := range ids[skip:end] {
+		values = append(values, selectFields(s.collections[route.collection].entities[id], query.Get("$select")))
+	}
+
+	out := map[string]interface{}{
+		"value": values,
+	}
+	if end < len(ids) {
+		next := *r.URL
+		nextQuery := next.Query()
+		nextQuery.Set("$top", strconv.Itoa(top))
+		nextQuery.Set("$skiptoken", strconv.Itoa(end))
+		next.RawQuery = nextQuery.Encode()
+		next.Scheme = "http"
+		if r.TLS != nil {
+			next.Scheme = "https"
+		}
+		next.Host = r.Host
+		out["@odata.nextLink"] = next.String()
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request, route *route) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.exists(route) {
+		writeNotFound(w, route)
+		return
+	}
+	writeJSON(w, http.StatusOK, selectFields(s.collections[route.collection].entities[route.id], r.URL.Query().Get("$select")))
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request, route *route) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	if definition := s.loader.GetResourceDefinition(s.apiVersion, route.template); definition != nil {
+		diagnostics := definition.Diagnose(body, "", &types.ValidationOptions{
+			UnknownProperties: types.UnknownPropertyError,
+			SkipRequired:      true,
+		})
+		errs := append(diagnostics.Errors(), createOnlyErrors(definition, body)...)
+		if len(errs) != 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.exists(route) {
+		writeNotFound(w, route)
+		return
+	}
+	entity := s.collections[route.collection].entities[route.id]
+	for key, value := range body {
+		if key == "id" {
+			continue
+		}
+		entity[key] = value
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) delete(w http.ResponseWriter, route *route) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.exists(route) {
+		writeNotFound(w, route)
+		return
+	}
+	c := s.collections[route.collection]
+	delete(c.entities, route.id)
+	for i, id := range c.ids {
+		if id == route.id {
+			c.ids = append(c.ids[:i:i], c.ids[i+1:]...)
+			break
+		}
+	}
+
+	// the contained collections are deleted with the entity
+	prefix := fmt.Sprintf("%s/%s/", route.collection, route.id)
+	for key := range s.collections {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.collections, key)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// exists returns true if the entity addressed by the route exists, the top level collections always exist.
+func (s *Server) exists(route *route) bool {
+	if route == nil {
+		return true
+	}
+	if route.id == "" {
+		return s.exists(route.parent)
+	}
+	c := s.collections[route.collection]
+	if c == nil {
+		return false
+	}
+	_, ok := c.entities[route.id]
+	return ok
+}
+
+func (s *Server) collection(path string) *collection {
+	if s.collections[path] == nil {
+		s.collections[path] = &collection{
+			entities: make(map[string]map[string]interface{}),
+		}
+	}
+	return s.collections[path]
+}
+
+// assignReadOnlyFields assigns the read-only fields which are not specified, the date-time fields are assigned with
+// the current time, and the id fields are assigned with new ids.
+func assignReadOnlyFields(definition *types.ResourceType, entity map[string]interface{}) {
+	if definition.Body == nil {
+		return
+	}
+	objectType, ok := definition.Body.Type.(*types.ObjectType)
+	if !ok {
+		return
+	}
+	for key, property := range objectType.Properties {
+		if !property.IsReadOnly() || entity[key] != nil || property.Type == nil {
+			continue
+		}
+		if _, ok := property.Type.Type.(*types.StringType); !ok {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(key, "DateTime"):
+			entity[key] = time.Now().UTC().Format(time.RFC3339)
+		case strings.HasSuffix(key, "Id"):
+			entity[key] = newID()
+		}
+	}
+}
+
+// createOnlyErrors returns the errors of the create-only properties specified in the body of PATCH.
+func createOnlyErrors(definition *types.ResourceType, body map[string]interface{}) []error {
+	if definition.Body == nil {
+		return nil
+	}
+	objectType, ok := definition.Body.Type.(*types.ObjectType)
+	if !ok {
+		return nil
+	}
+	out := make([]error, 0)
+	for key := range body {
+		if property, ok := objectType.Properties[key]; ok && property.IsDeployTimeConstant() {
+			out = append(out, types.ErrorCommon("."+key, "it can only be specified when the resource is created"))
+		}
+	}
+	return out
+}
+
+// selectFields returns the fields specified by `$select`, the id is always returned.
+func selectFields(entity map[string]interface{}, selects string) map[string]interface{} {
+	if selects == "" {
+		return entity
+	}
+	out := make(map[string]interface{})
+	for _, key := range append(strings.Split(selects, ","), "id", "@odata.id") {
+		key = strings.TrimSpace(key)
+		if value, ok := entity[key]; ok {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+func readBody(w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body == nil {
+		writeError(w, http.StatusBadRequest, "BadRequest", "Unable to read JSON request payload. Please ensure Content-Type header is set and payload is of valid JSON format.")
+		return nil, false
+	}
+	return body, true
+}
+
+func writeNotFound(w http.ResponseWriter, route *route) {
+	writeError(w, http.StatusNotFound, "Request_ResourceNotFound", fmt.Sprintf("Resource '%s' does not exist or one of its queried reference-property objects are not present.", route.id))
+}
+
+func writeValidationErrors(w http.ResponseWriter, errs []error) {
+	messages := make([]string, 0)
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	sort.Strings(messages)
+	details := make([]interface{}, 0)
+	for _, message := range messages {
+		details = append(details, map[string]interface{}{
+			"code":    "InvalidValue",
+			"message": message,
+		})
+	}
+	writeODataError(w, http.StatusBadRequest, "Request_BadRequest", messages[0], details)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code string, message string) {
+	writeODataError(w, statusCode, code, message, nil)
+}
+
+// writeODataError writes the error in the format of https://learn.microsoft.com/graph/errors
+func writeODataError(w http.ResponseWriter, statusCode int, code string, message string, details []interface{}) {
+	requestId := newID()
+	body := map[string]interface{}{
+		"code":    code,
+		"message": message,
+		"innerError": map[string]interface{}{
+			"date":              time.Now().UTC().Format(time.RFC3339),
+			"request-id":        requestId,
+			"client-request-id": requestId,
+		},
+	}
+	if len(details) != 0 {
+		body["details"] = details
+	}
+	writeJSON(w, statusCode, map[string]interface{}{
+		"error": body,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// newID returns a random UUID.
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
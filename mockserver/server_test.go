@@ -0,0 +1,241 @@
+package mockserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/ms-henglu/go-msgraph-types/types"
+)
+
+func newTestServer() *httptest.Server {
+	return httptest.NewServer(NewServer(types.DefaultMSGraphSchemaLoader(), "v1.0"))
+}
+
+func doRequest(t *testing.T, method, url string, body interface{}) (int, map[string]interface{}) {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var out map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return resp.StatusCode, out
+}
+
+func Test_Server(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	statusCode, application := doRequest(t, http.MethodPost, server.URL+"/v1.0/applications", map[string]interface{}{
+		"displayName": "test",
+	})
+	if statusCode != http.StatusCreated {
+		t.Fatalf("expect status code %d but got %d: %v", http.StatusCreated, statusCode, application)
+	}
+	id, _ := application["id"].(string)
+	if id == "" {
+		t.Fatalf("expect the id to be assigned but got %v", application)
+	}
+
+	statusCode, body := doRequest(t, http.MethodPatch, server.URL+"/applications/"+id, map[string]interface{}{
+		"displayName": "updated",
+	})
+	if statusCode != http.StatusNoContent {
+		t.Errorf("expect status code %d but got %d: %v", http.StatusNoContent, statusCode, body)
+	}
+
+	statusCode, body = doRequest(t, http.MethodGet, server.URL+"/applications/"+id+"?$select=displayName", nil)
+	if statusCode != http.StatusOK || body["displayName"] != "updated" || body["id"] != id || len(body) != 2 {
+		t.Errorf("expect the selected fields of the updated application but got %d: %v", statusCode, body)
+	}
+
+	statusCode, body = doRequest(t, http.MethodPost, server.URL+"/applications/"+id+"/extensionProperties", map[string]interface{}{
+		"name":          "test",
+		"dataType":      "String",
+		"targetObjects": []interface{}{"User"},
+	})
+	if statusCode != http.StatusCreated {
+		t.Errorf("expect status code %d but got %d: %v", http.StatusCreated, statusCode, body)
+	}
+
+	statusCode, body = doRequest(t, http.MethodDelete, server.URL+"/applications/"+id, nil)
+	if statusCode != http.StatusNoContent {
+		t.Errorf("expect status code %d but got %d: %v", http.StatusNoContent, statusCode, body)
+	}
+
+	statusCode, body = doRequest(t, http.MethodGet, server.URL+"/applications/"+id+"/extensionProperties", nil)
+	if statusCode != http.StatusNotFound || body["error"] == nil {
+		t.Errorf("expect status code %d with an error but got %d: %v", http.StatusNotFound, statusCode, body)
+	}
+}
+
+func Test_ServerValidation(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	statusCode, body := doRequest(t, http.MethodPost, server.URL+"/applications", map[string]interface{}{
+		"displayName": 1,
+	})
+	if statusCode != http.StatusBadRequest {
+		t.Fatalf("expect status code %d but got %d: %v", http.StatusBadRequest, statusCode, body)
+	}
+	odataError, _ := body["error"].(map[string]interface{})
+	if odataError["code"] != "Request_BadRequest" || odataError["message"] == "" || odataError["innerError"] == nil {
+		t.Errorf("expect an OData error but got %v", body)
+	}
+
+	statusCode, body = doRequest(t, http.MethodGet, server.URL+"/applications/not-exist", nil)
+	if statusCode != http.StatusNotFound {
+		t.Errorf("expect status code %d but got %d: %v", http.StatusNotFound, statusCode, body)
+	}
+
+	for _, path := range []string{"/notExist", "/v1.0", "/v1.0/", "/"} {
+		statusCode, body = doRequest(t, http.MethodGet, server.URL+path, nil)
+		if statusCode != http.StatusNotFound {
+			t.Errorf("expect status code %d for %s but got %d: %v", http.StatusNotFound, path, statusCode, body)
+		}
+	}
+}
+
+func Test_ServerPaging(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	for i := 0; i < 3; i++ {
+		if statusCode, body := doRequest(t, http.MethodPost, server.URL+"/groups", map[string]interface{}{
+			"displayName":     "test",
+			"mailEnabled":     false,
+			"mailNickname":    "test",
+			"securityEnabled": true,
+		}); statusCode != http.StatusCreated {
+			t.Fatalf("expect status code %d but got %d: %v", http.StatusCreated, statusCode, body)
+		}
+	}
+
+	count := 0
+	pages := 0
+	next := server.URL + "/groups?$top=2"
+	for next != "" {
+		statusCode, body := doRequest(t, http.MethodGet, next, nil)
+		if statusCode != http.StatusOK {
+			t.Fatalf("expect status code %d but got %d: %v", http.StatusOK, statusCode, body)
+		}
+		values, _ := body["value"].([]interface{})
+		count += len(values)
+		pages++
+		next, _ = body["@odata.nextLink"].(string)
+	}
+	if count != 3 || pages != 2 {
+		t.Errorf("expect %d groups in %d pages but got %d groups in %d pages", 3, 2, count, pages)
+	}
+
+	for _, top := range []string{"0", "-1", "a"} {
+		statusCode, body := doRequest(t, http.MethodGet, server.URL+"/groups?$top="+top, nil)
+		if statusCode != http.StatusBadRequest {
+			t.Errorf("expect status code %d for $top=%s but got %d: %v", http.StatusBadRequest, top, statusCode, body)
+		}
+	}
+}
+
+func Test_ServerCreateOnly(t *testing.T) {
+	server := httptest.NewServer(NewServer(types.NewMSGraphSchemaLoader(os.DirFS("../types/testdata")), "v1.0"))
+	defer server.Close()
+
+	statusCode, widget := doRequest(t, http.MethodPost, server.URL+"/widgets", map[string]interface{}{
+		"@odata.type": "#microsoft.graph.widget",
+		"displayName": "test",
+		"region":      "west",
+	})
+	if statusCode != http.StatusCreated {
+		t.Fatalf("expect status code %d but got %d: %v", http.StatusCreated, statusCode, widget)
+	}
+	id, _ := widget["id"].(string)
+
+	// the region can only be set when the widget is created
+	statusCode, body := doRequest(t, http.MethodPatch, server.URL+"/widgets/"+id, map[string]interface{}{
+		"region": "east",
+	})
+	if statusCode != http.StatusBadRequest {
+		t.Errorf("expect status code %d but got %d: %v", http.StatusBadRequest, statusCode, body)
+	}
+
+	statusCode, body = doRequest(t, http.MethodPatch, server.URL+"/widgets/"+id, map[string]interface{}{
+		"displayName": "updated",
+	})
+	if statusCode != http.StatusNoContent {
+		t.Errorf("expect status code %d but got %d: %v", http.StatusNoContent, statusCode, body)
+	}
+
+	_, body = doRequest(t, http.MethodGet, server.URL+"/widgets/"+id, nil)
+	if body["region"] != "west" || body["displayName"] != "updated" {
+		t.Errorf("expect the region %q and the display name %q but got %v", "west", "updated", body)
+	}
+}
+
+func Test_ServerReferences(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	_, application := doRequest(t, http.MethodPost, server.URL+"/applications", map[string]interface{}{
+		"displayName": "test",
+	})
+	id, _ := application["id"].(string)
+
+	statusCode, body := doRequest(t, http.MethodPost, server.URL+"/applications/"+id+"/owners/$ref", map[string]interface{}{
+		"@odata.id": "https://graph.microsoft.com/v1.0/directoryObjects/1234",
+	})
+	if statusCode != http.StatusNoContent {
+		t.Fatalf("expect status code %d but got %d: %v", http.StatusNoContent, statusCode, body)
+	}
+
+	_, body = doRequest(t, http.MethodGet, server.URL+"/applications/"+id+"/owners/$ref", nil)
+	if values, _ := body["value"].([]interface{}); len(values) != 1 {
+		t.Errorf("expect %d reference but got %v", 1, body)
+	}
+
+	statusCode, body = doRequest(t, http.MethodDelete, server.URL+"/applications/"+id+"/owners/1234/$ref", nil)
+	if statusCode != http.StatusNoContent {
+		t.Errorf("expect status code %d but got %d: %v", http.StatusNoContent, statusCode, body)
+	}
+}
+
+func Test_ServerSamples(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	loader := types.DefaultMSGraphSchemaLoader()
+	definition := loader.GetResourceDefinition("v1.0", "/applications")
+	statusCode, application := doRequest(t, http.MethodPost, server.URL+"/applications", definition.Sample(&types.SampleOptions{Mode: types.SampleFull}))
+	if statusCode != http.StatusCreated {
+		t.Fatalf("expect status code %d but got %d: %v", http.StatusCreated, statusCode, application)
+	}
+	id, _ := application["id"].(string)
+
+	for _, mode := range []types.SampleMode{types.SampleMinimal, types.SampleFull} {
+		definition := loader.GetResourceDefinition("v1.0", "/applications/{application-id}/owners/$ref")
+		body := definition.Sample(&types.SampleOptions{Mode: mode, Rand: rand.New(rand.NewSource(int64(mode)))})
+		statusCode, response := doRequest(t, http.MethodPost, server.URL+"/applications/"+id+"/owners/$ref", body)
+		if statusCode != http.StatusNoContent {
+			t.Errorf("expect status code %d but got %d: %v", http.StatusNoContent, statusCode, response)
+		}
+	}
+}
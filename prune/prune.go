@@ -0,0 +1,337 @@
+package prune
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Options selects the paths kept in the pruned document, a path is kept if it matches any of the options.
+type Options struct {
+	// Resources are the resource URLs, e.g. `/applications`, the paths of the collection, the items and the
+	// alternate keys of the resources are kept.
+	Resources []string
+	// Prefixes are the path prefixes, e.g. `/identity/conditionalAccess`.
+	Prefixes []string
+	// Tags are the prefixes of the operation tags, e.g. `applications.application`.
+	Tags []string
+}
+
+// Prune returns a minimal OpenAPI document which only contains the selected paths and the components referenced by
+// them transitively, it can be loaded by types.NewMSGraphSchemaLoader like the full document.
+// The item paths of the entity sets of the navigation targets are also kept, e.g. `/directoryObjects/{id}` for
+// `owners`, so that the `@odata.bind` references to them can be resolved. The entity sets of the derived types, e.g.
+// `/users/{id}`, are not kept unless they're selected, because their schemas would pull in most of the document.
+func Prune(input []byte, options Options) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(input, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %+v", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the document is not an OpenAPI document")
+	}
+	root := doc.Content[0]
+
+	paths := mappingValue(root, "paths")
+	if paths == nil || paths.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the document doesn't have any paths")
+	}
+
+	pruner := newPruner(mappingValue(root, "components"))
+
+	kept := make(map[string]bool)
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		path, pathItem := paths.Content[i].Value, paths.Content[i+1]
+		if options.match(path, operationTags(pathItem)) {
+			kept[path] = true
+			pruner.collect(pathItem)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("no paths match the options")
+	}
+
+	targets := pruner.navigationTargets()
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		path, pathItem := paths.Content[i].Value, paths.Content[i+1]
+		if !kept[path] && entitySetItemPathRegex.MatchString(normalizePath(path)) && targets[responseSchemaName(pathItem)] {
+			kept[path] = true
+			pruner.collect(pathItem)
+		}
+	}
+
+	keptPaths := make([]*yaml.Node, 0)
+	tags := make(map[string]bool)
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		if !kept[paths.Content[i].Value] {
+			continue
+		}
+		keptPaths = append(keptPaths, paths.Content[i], paths.Content[i+1])
+		for _, tag := range operationTags(paths.Content[i+1]) {
+			tags[tag] = true
+		}
+	}
+	paths.Content = keptPaths
+
+	pruner.pruneComponents()
+	pruner.pruneDiscriminators()
+	pruneTags(root, tags)
+	if len(pruner.components.Content) == 0 {
+		removeMappingKey(root, "components")
+	}
+
+	buffer := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode document: %+v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode document: %+v", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+var pathVariableRegex = regexp.MustCompile(`\{[^}]*\}`)
+
+// entitySetItemPathRegex matches the normalized item paths of the entity sets, e.g. `/directoryObjects/{}`.
+var entitySetItemPathRegex = regexp.MustCompile(`^/[^/{}()]+/\{\}$`)
+
+func normalizePath(path string) string {
+	return pathVariableRegex.ReplaceAllString(path, "{}")
+}
+
+func (o Options) match(path string, tags []string) bool {
+	normalizedPath := normalizePath(path)
+	for _, resource := range o.Resources {
+		if !strings.HasPrefix(resource, "/") {
+			resource = "/" + resource
+		}
+		normalizedResource := normalizePath(resource)
+		if normalizedPath == normalizedResource || normalizedPath == normalizedResource+"/{}" || strings.HasPrefix(path, resource+"(") {
+			return true
+		}
+	}
+	for _, prefix := range o.Prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	for _, prefix := range o.Tags {
+		for _, tag := range tags {
+			if strings.HasPrefix(tag, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var operationKeys = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+func operationTags(pathItem *yaml.Node) []string {
+	out := make([]string, 0)
+	for _, key := range operationKeys {
+		operation := mappingValue(pathItem, key)
+		if operation == nil {
+			continue
+		}
+		if tags := mappingValue(operation, "tags"); tags != nil && tags.Kind == yaml.SequenceNode {
+			for _, tag := range tags.Content {
+				out = append(out, tag.Value)
+			}
+		}
+	}
+	return out
+}
+
+// pruner collects the components referenced by the kept paths and removes the others.
+type pruner struct {
+	components *yaml.Node
+	// referenced contains the referenced components, keyed by the kind, e.g. `schemas`, and then the name
+	referenced map[string]map[string]bool
+}
+
+func newPruner(components *yaml.Node) *pruner {
+	if components == nil || components.Kind != yaml.MappingNode {
+		components = &yaml.Node{Kind: yaml.MappingNode}
+	}
+	return &pruner{
+		components: components,
+		referenced: make(map[string]map[string]bool),
+	}
+}
+
+// collect marks the components referenced by the node as kept, and then collects the references inside them.
+func (p *pruner) collect(node *yaml.Node) {
+	if node == nil {
+		return
+	}
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "$ref" && node.Content[i+1].Kind == yaml.ScalarNode {
+				p.collectReference(node.Content[i+1].Value)
+			}
+		}
+	}
+	for _, child := range node.Content {
+		p.collect(child)
+	}
+}
+
+func (p *pruner) collectReference(ref string) {
+	kind, name, ok := parseComponentReference(ref)
+	if !ok || p.referenced[kind][name] {
+		return
+	}
+	if p.referenced[kind] == nil {
+		p.referenced[kind] = make(map[string]bool)
+	}
+	p.referenced[kind][name] = true
+	p.collect(mappingValue(mappingValue(p.components, kind), name))
+}
+
+// navigationTargets returns the names of the schemas referenced by the navigation properties of the kept schemas.
+func (p *pruner) navigationTargets() map[string]bool {
+	out := make(map[string]bool)
+	schemas := mappingValue(p.components, "schemas")
+	var walk func(node *yaml.Node, navigation bool)
+	walk = func(node *yaml.Node, navigation bool) {
+		if node.Kind == yaml.MappingNode {
+			if value := mappingValue(node, "x-ms-navigationProperty"); value != nil && value.Value == "true" {
+				navigation = true
+			}
+			if ref := mappingValue(node, "$ref"); navigation && ref != nil {
+				if kind, name, ok := parseComponentReference(ref.Value); ok && kind == "schemas" {
+					out[name] = true
+				}
+			}
+		}
+		for _, child := range node.Content {
+			walk(child, navigation)
+		}
+	}
+	for name := range p.referenced["schemas"] {
+		if schema := mappingValue(schemas, name); schema != nil {
+			walk(schema, false)
+		}
+	}
+	return out
+}
+
+// responseSchemaName returns the name of the schema referenced by the json response of the GET operation.
+func responseSchemaName(pathItem *yaml.Node) string {
+	response := mappingValue(mappingValue(mappingValue(pathItem, "get"), "responses"), "200")
+	schema := mappingValue(mappingValue(mappingValue(response, "content"), "application/json"), "schema")
+	if ref := mappingValue(schema, "$ref"); ref != nil {
+		if kind, name, ok := parseComponentReference(ref.Value); ok && kind == "schemas" {
+			return name
+		}
+	}
+	return ""
+}
+
+// pruneComponents removes the components which are not referenced, the security schemes are kept because they're
+// referenced by names instead of references.
+func (p *pruner) pruneComponents() {
+	content := make([]*yaml.Node, 0)
+	for i := 0; i+1 < len(p.components.Content); i += 2 {
+		kind, values := p.components.Content[i], p.components.Content[i+1]
+		if kind.Value == "securitySchemes" || values.Kind != yaml.MappingNode {
+			content = append(content, kind, values)
+			continue
+		}
+		kept := make([]*yaml.Node, 0)
+		for j := 0; j+1 < len(values.Content); j += 2 {
+			if p.referenced[kind.Value][values.Content[j].Value] {
+				kept = append(kept, values.Content[j], values.Content[j+1])
+			}
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		values.Content = kept
+		content = append(content, kind, values)
+	}
+	p.components.Content = content
+}
+
+// pruneDiscriminators removes the discriminator mappings to the schemas which are removed, e.g. the derived types of
+// `microsoft.graph.entity` which are not used by the kept paths.
+func (p *pruner) pruneDiscriminators() {
+	schemas := mappingValue(p.components, "schemas")
+	if schemas == nil {
+		return
+	}
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node.Kind == yaml.MappingNode {
+			if mapping := mappingValue(mappingValue(node, "discriminator"), "mapping"); mapping != nil && mapping.Kind == yaml.MappingNode {
+				kept := make([]*yaml.Node, 0)
+				for i := 0; i+1 < len(mapping.Content); i += 2 {
+					kind, name, ok := parseComponentReference(mapping.Content[i+1].Value)
+					if !ok || p.referenced[kind][name] {
+						kept = append(kept, mapping.Content[i], mapping.Content[i+1])
+					}
+				}
+				mapping.Content = kept
+			}
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(schemas)
+}
+
+// pruneTags removes the tags which are not used by the kept operations.
+func pruneTags(root *yaml.Node, used map[string]bool) {
+	tags := mappingValue(root, "tags")
+	if tags == nil || tags.Kind != yaml.SequenceNode {
+		return
+	}
+	kept := make([]*yaml.Node, 0)
+	for _, tag := range tags.Content {
+		if name := mappingValue(tag, "name"); name != nil && used[name.Value] {
+			kept = append(kept, tag)
+		}
+	}
+	tags.Content = kept
+}
+
+// parseComponentReference parses the local references like `#/components/schemas/microsoft.graph.entity`.
+func parseComponentReference(ref string) (string, string, bool) {
+	if !strings.HasPrefix(ref, "#/components/") {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(ref, "#/components/"), "/", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	replacer := strings.NewReplacer("~1", "/", "~0", "~")
+	return replacer.Replace(parts[0]), replacer.Replace(parts[1]), true
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func removeMappingKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
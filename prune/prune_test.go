@@ -0,0 +1,172 @@
+package prune
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	typesEmbed "github.com/ms-henglu/go-msgraph-types/embed"
+	"github.com/ms-henglu/go-msgraph-types/types"
+)
+
+func Test_Prune(t *testing.T) {
+	data, err := fs.ReadFile(typesEmbed.StaticFiles, "openapi/v1.0/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Prune(data, Options{Resources: []string{"/applications"}})
+	if err != nil {
+		t.Fatalf("expect no error but got %+v", err)
+	}
+	if len(out) >= len(data) {
+		t.Errorf("expect the pruned document to be smaller than %d bytes but got %d bytes", len(data), len(out))
+	}
+
+	loader := types.NewMSGraphSchemaLoader(fstest.MapFS{
+		"openapi/v1.0/openapi.yaml": &fstest.MapFile{Data: out},
+	})
+	resources := loader.ListResources("v1.0")
+	if len(resources) != 1 || resources[0].Url != "/applications" {
+		t.Fatalf("expect only the resource /applications but got %v", resources)
+	}
+	if loader.GetResourceDefinition("v1.0", "/applications") == nil {
+		t.Errorf("failed to load resource definition for %s api-version %s", "/applications", "v1.0")
+	}
+	if loader.GetResourceDefinition("v1.0", "/groups") != nil {
+		t.Errorf("expect the resource /groups to be pruned")
+	}
+	if strings.Contains(string(out), "microsoft.graph.group:") {
+		t.Errorf("expect the schema microsoft.graph.group to be pruned")
+	}
+}
+
+func Test_PruneNoMatch(t *testing.T) {
+	data, err := fs.ReadFile(typesEmbed.StaticFiles, "openapi/v1.0/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Prune(data, Options{Prefixes: []string{"/notExist"}}); err == nil {
+		t.Errorf("expect an error but got nil")
+	}
+}
+
+const navigationDocument = `openapi: 3.0.4
+info:
+  title: navigation
+  version: v1.0
+paths:
+  /widgets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/microsoft.graph.widget'
+      responses:
+        '201':
+          description: Created
+  /widgets/{widget-id}:
+    get:
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/microsoft.graph.widget'
+    delete:
+      responses:
+        '204':
+          description: Deleted
+  /directoryObjects/{directoryObject-id}:
+    get:
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/microsoft.graph.directoryObject'
+  /users/{user-id}:
+    get:
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/microsoft.graph.user'
+components:
+  schemas:
+    microsoft.graph.widget:
+      title: widget
+      type: object
+      properties:
+        displayName:
+          type: string
+        owners:
+          type: array
+          items:
+            $ref: '#/components/schemas/microsoft.graph.directoryObject'
+          x-ms-navigationProperty: true
+    microsoft.graph.directoryObject:
+      title: directoryObject
+      type: object
+      properties:
+        id:
+          type: string
+        '@odata.type':
+          type: string
+          default: '#microsoft.graph.directoryObject'
+    microsoft.graph.user:
+      allOf:
+        - $ref: '#/components/schemas/microsoft.graph.directoryObject'
+        - title: user
+          type: object
+          properties:
+            '@odata.type':
+              type: string
+              default: '#microsoft.graph.user'
+`
+
+func Test_PruneNavigationTargets(t *testing.T) {
+	out, err := Prune([]byte(navigationDocument), Options{Resources: []string{"/widgets"}})
+	if err != nil {
+		t.Fatalf("expect no error but got %+v", err)
+	}
+	if !strings.Contains(string(out), "/directoryObjects/{directoryObject-id}:") {
+		t.Errorf("expect the entity set of the navigation target to be kept")
+	}
+	if strings.Contains(string(out), "/users/{user-id}:") || strings.Contains(string(out), "microsoft.graph.user:") {
+		t.Errorf("expect the entity set of the derived type to be pruned")
+	}
+
+	loader := types.NewMSGraphSchemaLoader(fstest.MapFS{
+		"openapi/v1.0/openapi.yaml": &fstest.MapFile{Data: out},
+	})
+	def := loader.GetResourceDefinition("v1.0", "/widgets")
+	if def == nil {
+		t.Fatalf("failed to load resource definition for %s api-version %s", "/widgets", "v1.0")
+	}
+
+	cases := []struct {
+		url         string
+		expectError bool
+	}{
+		{"https://graph.microsoft.com/v1.0/directoryObjects/00000000-0000-0000-0000-000000000000", false},
+		{"https://graph.microsoft.com/v1.0/users/00000000-0000-0000-0000-000000000000", true},
+	}
+
+	for _, c := range cases {
+		body := map[string]interface{}{
+			"displayName":       "foo",
+			"owners@odata.bind": []interface{}{c.url},
+		}
+		errors := def.Validate(body, "")
+		if c.expectError != (len(errors) != 0) {
+			t.Errorf("expect error %v but got %v for url %s", c.expectError, errors, c.url)
+		}
+	}
+}
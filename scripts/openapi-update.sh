@@ -16,6 +16,10 @@ main() {
     cp -r $source_dir/v1.0/openapi.yaml $target_dir/v1.0/openapi.yaml
     cp -r $source_dir/beta/openapi.yaml $target_dir/beta/openapi.yaml
     echo "done"
+    echo "copying new permission files..."
+    rm -f $target_dir/permissions.json
+    cp $ROOTDIR/embed/msgraph-metadata/permissions/new/permissions.json $target_dir/permissions.json
+    echo "done"
 }
 
 main "$@"
\ No newline at end of file
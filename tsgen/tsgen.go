@@ -0,0 +1,412 @@
+package tsgen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ms-henglu/go-msgraph-types/types"
+)
+
+// Generate returns the TypeScript declarations of the request bodies of the resources, see Emit. The named object
+// types are declared by their type definitions, so that the declarations don't depend on the flags of the resources,
+// and the types derived from the referenced types are also declared to complete the discriminated unions.
+func Generate(loader *types.MSGraphSchemaLoader, apiVersion string, urls []string) (string, error) {
+	definition := func(name string) types.TypeBase {
+		if t := loader.GetTypeDefinition(apiVersion, name); t != nil {
+			if objectType, ok := (*t).(*types.ObjectType); ok {
+				return objectType
+			}
+		}
+		return nil
+	}
+
+	roots := make([]types.TypeBase, 0, len(urls))
+	for _, url := range urls {
+		resource := loader.GetResourceDefinition(apiVersion, url)
+		if resource == nil {
+			return "", fmt.Errorf("resource %s is not found in api-version %s", url, apiVersion)
+		}
+		if resource.Body == nil || resource.Body.Type == nil {
+			continue
+		}
+		root := resource.Body.Type
+		if objectType, ok := root.(*types.ObjectType); ok && objectType.QualifiedName() != "" {
+			if t := definition(objectType.QualifiedName()); t != nil {
+				root = t
+			}
+		}
+		roots = append(roots, root)
+	}
+
+	e := newEmitter()
+	e.derivedTypes = func(name string) []types.TypeBase {
+		out := make([]types.TypeBase, 0)
+		for _, derived := range loader.ListDerivedTypes(apiVersion, name) {
+			if t := definition(derived.Name); t != nil {
+				out = append(out, t)
+			}
+		}
+		return out
+	}
+	return e.emit(roots), nil
+}
+
+// Emit returns the TypeScript declarations of the types and the object types referenced by them. Each named object
+// type is an interface, the properties which aren't required are optional and the read-only properties are readonly.
+// The enums are unions of string literals, and `@odata.type` is the string literal of the type, so that the base type
+// and its derived types form a discriminated union, e.g. `DirectoryObjectUnion = DirectoryObject | User`, which is the
+// type of the properties referencing the base type. The object types with the same fully-qualified name share one
+// interface, the interfaces are named by the titles and the colliding names are qualified by the namespaces, e.g.
+// `Group` and `TermStoreGroup`. The declarations are sorted by name, so that the output is stable.
+func Emit(roots []types.TypeBase) string {
+	return newEmitter().emit(roots)
+}
+
+type emitter struct {
+	// names are the interface names of the named object types
+	names map[*types.ObjectType]string
+	// objects are the named object types keyed by the interface names
+	objects map[string]*types.ObjectType
+	// qualifiedNames are the interface names keyed by the fully-qualified names, e.g. `microsoft.graph.user`
+	qualifiedNames map[string]string
+	// derived are the interface names of the derived types keyed by the interface names of the base types
+	derived map[string][]string
+
+	// collected are the named object types in the order they're found, the object types with the same fully-qualified
+	// name are collected once, e.g. the copies of the type definitions with the flags of the resources
+	collected []*types.ObjectType
+	// declared are the collected object types keyed by the fully-qualified names
+	declared map[string]*types.ObjectType
+	// visited are the types which are collected or being collected
+	visited map[types.TypeBase]bool
+	// derivedTypes returns the types derived from the type of the fully-qualified name, it's nil if the derived types
+	// aren't looked up
+	derivedTypes func(name string) []types.TypeBase
+}
+
+func newEmitter() *emitter {
+	return &emitter{
+		names:          make(map[*types.ObjectType]string),
+		objects:        make(map[string]*types.ObjectType),
+		qualifiedNames: make(map[string]string),
+		derived:        make(map[string][]string),
+		declared:       make(map[string]*types.ObjectType),
+		visited:        make(map[types.TypeBase]bool),
+	}
+}
+
+func (e *emitter) emit(roots []types.TypeBase) string {
+	// the roots are collected by their names, so that the output doesn't depend on the order of the resources
+	roots = append([]types.TypeBase(nil), roots...)
+	sort.SliceStable(roots, func(i, j int) bool {
+		return rootKey(roots[i]) < rootKey(roots[j])
+	})
+	for _, root := range roots {
+		e.collect(root)
+	}
+	e.assignNames()
+	for name, objectType := range e.objects {
+		for _, baseType := range objectType.BaseTypes {
+			if baseName := e.qualifiedNames[baseType]; baseName != "" {
+				e.derived[baseName] = append(e.derived[baseName], name)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(e.objects))
+	for name := range e.objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	builder := &strings.Builder{}
+	builder.WriteString("// Code generated by msgraph-types. DO NOT EDIT.\n")
+	for _, name := range names {
+		builder.WriteString("\n")
+		if derived := e.derived[name]; len(derived) != 0 {
+			sort.Strings(derived)
+			fmt.Fprintf(builder, "export type %s = %s;\n\n", unionName(name), strings.Join(append([]string{name}, derived...), " | "))
+		}
+		fmt.Fprintf(builder, "export interface %s ", name)
+		e.writeObject(builder, e.objects[name], "", make(map[*types.ObjectType]bool))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// collect finds the named object types which are referenced by the type, and the types derived from them.
+func (e *emitter) collect(t types.TypeBase) {
+	if t == nil || e.visited[t] {
+		return
+	}
+	e.visited[t] = true
+	switch v := t.(type) {
+	case *types.ObjectType:
+		// derivedFrom is the fully-qualified name of the type whose derived types are collected
+		derivedFrom := ""
+		if v.Name != "" {
+			qualifiedName := v.QualifiedName()
+			if qualifiedName == "" {
+				e.collected = append(e.collected, v)
+			} else if _, ok := e.declared[qualifiedName]; !ok {
+				e.declared[qualifiedName] = v
+				e.collected = append(e.collected, v)
+				derivedFrom = qualifiedName
+			}
+		}
+		keys := make([]string, 0, len(v.Properties))
+		for key := range v.Properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if property := v.Properties[key]; property.Type != nil {
+				e.collect(property.Type.Type)
+			}
+		}
+		if v.AdditionalProperties != nil {
+			e.collect(v.AdditionalProperties.Type)
+		}
+		if derivedFrom != "" && e.derivedTypes != nil {
+			for _, derived := range e.derivedTypes(derivedFrom) {
+				e.collect(derived)
+			}
+		}
+	case *types.ArrayType:
+		if v.ItemType != nil {
+			e.collect(v.ItemType.Type)
+		}
+	case *types.UnionType:
+		for _, element := range v.Elements {
+			if element != nil {
+				e.collect(element.Type)
+			}
+		}
+	}
+}
+
+// assignNames names the interfaces of the collected object types by their titles. If the titles of the types collide,
+// the names are qualified by the namespaces except `microsoft.graph`, e.g. `Group` of `microsoft.graph.group` and
+// `TermStoreGroup` of `microsoft.graph.termStore.group`, and the names which still collide are numbered.
+func (e *emitter) assignNames() {
+	titles := make(map[string]int)
+	for _, objectType := range e.collected {
+		titles[interfaceName(objectType.Name)]++
+	}
+	candidates := make(map[*types.ObjectType]string)
+	for _, objectType := range e.collected {
+		name := interfaceName(objectType.Name)
+		if qualifiedName := objectType.QualifiedName(); titles[name] > 1 && qualifiedName != "" {
+			name = interfaceName(strings.TrimPrefix(qualifiedName, "microsoft.graph."))
+		}
+		candidates[objectType] = name
+	}
+
+	ordered := append([]*types.ObjectType(nil), e.collected...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if candidates[ordered[i]] != candidates[ordered[j]] {
+			return candidates[ordered[i]] < candidates[ordered[j]]
+		}
+		return ordered[i].QualifiedName() < ordered[j].QualifiedName()
+	})
+	for _, objectType := range ordered {
+		name := candidates[objectType]
+		for i := 2; e.objects[name] != nil; i++ {
+			name = fmt.Sprintf("%s%d", candidates[objectType], i)
+		}
+		e.objects[name] = objectType
+		e.names[objectType] = name
+		if qualifiedName := objectType.QualifiedName(); qualifiedName != "" {
+			e.qualifiedNames[qualifiedName] = name
+		}
+	}
+
+	// the copies of the collected object types share their interfaces
+	for t := range e.visited {
+		if objectType, ok := t.(*types.ObjectType); ok && objectType.Name != "" {
+			if _, ok := e.names[objectType]; !ok {
+				e.names[objectType] = e.qualifiedNames[objectType.QualifiedName()]
+			}
+		}
+	}
+}
+
+// rootKey returns the fully-qualified name or the title of the type, it's empty for the other types.
+func rootKey(t types.TypeBase) string {
+	if objectType, ok := t.(*types.ObjectType); ok {
+		if qualifiedName := objectType.QualifiedName(); qualifiedName != "" {
+			return qualifiedName
+		}
+		return objectType.Name
+	}
+	return ""
+}
+
+// writeObject writes the body of the object type, e.g. `{ id?: string; }`.
+func (e *emitter) writeObject(builder *strings.Builder, objectType *types.ObjectType, indent string, visited map[*types.ObjectType]bool) {
+	visited[objectType] = true
+	defer delete(visited, objectType)
+
+	keys := make([]string, 0, len(objectType.Properties))
+	for key := range objectType.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	builder.WriteString("{\n")
+	for _, key := range keys {
+		property := objectType.Properties[key]
+		writeComment(builder, property, indent+"  ")
+		builder.WriteString(indent + "  ")
+		if property.IsReadOnly() {
+			builder.WriteString("readonly ")
+		}
+		builder.WriteString(propertyName(key))
+		if !property.IsRequired() {
+			builder.WriteString("?")
+		}
+		builder.WriteString(": ")
+		if key == "@odata.type" {
+			if value := odataTypeValue(property); value != "" {
+				builder.WriteString(strconv.Quote(value) + ";\n")
+				continue
+			}
+		}
+		var propertyType types.TypeBase
+		if property.Type != nil {
+			propertyType = property.Type.Type
+		}
+		builder.WriteString(e.typeName(propertyType, indent+"  ", visited) + ";\n")
+	}
+	if objectType.AdditionalProperties != nil {
+		valueType := "unknown"
+		if len(objectType.Properties) == 0 {
+			valueType = e.typeName(objectType.AdditionalProperties.Type, indent+"  ", visited)
+		}
+		fmt.Fprintf(builder, "%s  [key: string]: %s;\n", indent, valueType)
+	}
+	builder.WriteString(indent + "}")
+}
+
+// typeName returns the TypeScript type, the named object types are referenced by their names and the other object
+// types are inlined.
+func (e *emitter) typeName(t types.TypeBase, indent string, visited map[*types.ObjectType]bool) string {
+	switch v := t.(type) {
+	case *types.ObjectType:
+		if name, ok := e.names[v]; ok {
+			if len(e.derived[name]) != 0 {
+				return unionName(name)
+			}
+			return name
+		}
+		if visited[v] {
+			return "unknown"
+		}
+		builder := &strings.Builder{}
+		e.writeObject(builder, v, indent, visited)
+		return builder.String()
+	case *types.ArrayType:
+		if v.ItemType == nil || v.ItemType.Type == nil {
+			return "unknown[]"
+		}
+		itemType := e.typeName(v.ItemType.Type, indent, visited)
+		if strings.Contains(itemType, " | ") && !strings.HasPrefix(itemType, "{") {
+			return "(" + itemType + ")[]"
+		}
+		return itemType + "[]"
+	case *types.UnionType:
+		elements := make([]string, 0, len(v.Elements))
+		for _, element := range v.Elements {
+			if element != nil {
+				elements = append(elements, e.typeName(element.Type, indent, visited))
+			}
+		}
+		if len(elements) == 0 {
+			return "unknown"
+		}
+		return strings.Join(elements, " | ")
+	case *types.StringType:
+		if len(v.Enum) != 0 {
+			values := make([]string, 0, len(v.Enum))
+			for _, value := range v.Enum {
+				values = append(values, strconv.Quote(value))
+			}
+			return strings.Join(values, " | ")
+		}
+		return "string"
+	case *types.NumberType:
+		return "number"
+	case *types.BooleanType:
+		return "boolean"
+	}
+	return "unknown"
+}
+
+func writeComment(builder *strings.Builder, property types.ObjectProperty, indent string) {
+	lines := make([]string, 0)
+	if property.Description != nil {
+		if description := strings.Join(strings.Fields(*property.Description), " "); description != "" {
+			lines = append(lines, strings.ReplaceAll(description, "*/", "*\\/"))
+		}
+	}
+	if property.Deprecated != nil {
+		deprecated := "@deprecated"
+		if property.Deprecated.Description != "" {
+			deprecated += " " + strings.ReplaceAll(property.Deprecated.Description, "*/", "*\\/")
+		}
+		lines = append(lines, deprecated)
+	}
+	switch len(lines) {
+	case 0:
+	case 1:
+		fmt.Fprintf(builder, "%s/** %s */\n", indent, lines[0])
+	default:
+		fmt.Fprintf(builder, "%s/**\n", indent)
+		for _, line := range lines {
+			fmt.Fprintf(builder, "%s * %s\n", indent, line)
+		}
+		fmt.Fprintf(builder, "%s */\n", indent)
+	}
+}
+
+// odataTypeValue returns the default value of `@odata.type`, e.g. `#microsoft.graph.user`.
+func odataTypeValue(property types.ObjectProperty) string {
+	if property.Type == nil {
+		return ""
+	}
+	if stringType, ok := property.Type.Type.(*types.StringType); ok && stringType.Default != nil {
+		return *stringType.Default
+	}
+	return ""
+}
+
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func propertyName(name string) string {
+	if identifierRegex.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+var wordRegex = regexp.MustCompile(`[A-Za-z0-9]+`)
+
+// interfaceName returns the name of the interface in PascalCase, e.g. `PasswordCredential` of `passwordCredential`.
+func interfaceName(name string) string {
+	builder := &strings.Builder{}
+	for _, word := range wordRegex.FindAllString(name, -1) {
+		builder.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	out := builder.String()
+	if out == "" || (out[0] >= '0' && out[0] <= '9') {
+		out = "Type" + out
+	}
+	return out
+}
+
+func unionName(name string) string {
+	return name + "Union"
+}
@@ -0,0 +1,147 @@
+package tsgen
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ms-henglu/go-msgraph-types/types"
+)
+
+func Test_Generate(t *testing.T) {
+	loader := types.NewMSGraphSchemaLoader(os.DirFS("../types/testdata"))
+	out, err := Generate(loader, "v1.0", []string{"/widgets", "/gadgets"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"export type DirectoryObjectUnion = DirectoryObject | Gadget;\n",
+		"export interface Widget {\n",
+		"  \"@odata.type\": \"#microsoft.graph.widget\";\n",
+		"  displayName: string;\n",
+		"  readonly createdDateTime?: string;\n",
+		"  color?: \"red\" | \"green\" | \"yellow\" | \"unknownFutureValue\" | \"blue\";\n",
+		"  /** @deprecated Use displayName instead. */\n  legacyName?: string;\n",
+		"  owners?: DirectoryObjectUnion[];\n",
+		"  settings?: WidgetSettings;\n",
+		"export interface WidgetSettings {\n",
+		"export interface Gadget {\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expect the declarations to contain %q but got:\n%s", expected, out)
+		}
+	}
+	if again, _ := Generate(loader, "v1.0", []string{"/gadgets", "/widgets"}); again != out {
+		t.Errorf("expect the declarations not to depend on the order of the resources")
+	}
+	if derived, _ := Generate(loader, "v1.0", []string{"/widgets"}); !strings.Contains(derived, "export type DirectoryObjectUnion = DirectoryObject | Gadget;\n") {
+		t.Errorf("expect the derived types which aren't referenced to be declared but got:\n%s", derived)
+	}
+	if _, err := Generate(loader, "v1.0", []string{"/notExist"}); err == nil {
+		t.Errorf("expect an error for the resource which doesn't exist")
+	}
+}
+
+func Test_EmitDiscriminatedUnion(t *testing.T) {
+	odataType := func(value string) types.ObjectProperty {
+		return types.ObjectProperty{
+			Type:  &types.TypeReference{Type: &types.StringType{Type: "string", Default: &value}},
+			Flags: []types.ObjectPropertyFlag{types.Required},
+		}
+	}
+	base := &types.ObjectType{
+		Type:       "object",
+		Name:       "directoryObject",
+		Properties: map[string]types.ObjectProperty{"@odata.type": odataType("#microsoft.graph.directoryObject")},
+	}
+	user := &types.ObjectType{
+		Type:      "object",
+		Name:      "user",
+		BaseTypes: []string{"microsoft.graph.directoryObject", "microsoft.graph.entity"},
+		Properties: map[string]types.ObjectProperty{
+			"@odata.type": odataType("#microsoft.graph.user"),
+			"userType": {
+				Type: &types.TypeReference{Type: &types.StringType{Type: "string", Enum: []string{"Member", "Guest"}}},
+			},
+		},
+	}
+	group := &types.ObjectType{
+		Type: "object",
+		Name: "group",
+		Properties: map[string]types.ObjectProperty{
+			"members": {Type: &types.TypeReference{Type: &types.ArrayType{Type: "array", ItemType: &types.TypeReference{Type: base}}}},
+		},
+	}
+
+	expected := `// Code generated by msgraph-types. DO NOT EDIT.
+
+export type DirectoryObjectUnion = DirectoryObject | User;
+
+export interface DirectoryObject {
+  "@odata.type": "#microsoft.graph.directoryObject";
+}
+
+export interface Group {
+  members?: DirectoryObjectUnion[];
+}
+
+export interface User {
+  "@odata.type": "#microsoft.graph.user";
+  userType?: "Member" | "Guest";
+}
+`
+	if out := Emit([]types.TypeBase{group, user}); out != expected {
+		t.Errorf("expect:\n%s\nbut got:\n%s", expected, out)
+	}
+}
+
+func Test_EmitCollidingNames(t *testing.T) {
+	odataType := func(value string) types.ObjectProperty {
+		return types.ObjectProperty{
+			Type:  &types.TypeReference{Type: &types.StringType{Type: "string", Default: &value}},
+			Flags: []types.ObjectPropertyFlag{types.Required},
+		}
+	}
+	group := &types.ObjectType{
+		Type: "object",
+		Name: "group",
+		Properties: map[string]types.ObjectProperty{
+			"@odata.type": odataType("#microsoft.graph.group"),
+			"displayName": {Type: &types.TypeReference{Type: &types.StringType{Type: "string"}}},
+		},
+	}
+	groupCopy := *group
+	groupCopy.Properties = map[string]types.ObjectProperty{
+		"@odata.type": odataType("#microsoft.graph.group"),
+		"displayName": {Type: &types.TypeReference{Type: &types.StringType{Type: "string"}}, Flags: []types.ObjectPropertyFlag{types.CreateOnly}},
+	}
+	termStoreGroup := &types.ObjectType{
+		Type: "object",
+		Name: "group",
+		Properties: map[string]types.ObjectProperty{
+			"@odata.type": odataType("#microsoft.graph.termStore.group"),
+			"scope":       {Type: &types.TypeReference{Type: &types.StringType{Type: "string"}}},
+		},
+	}
+
+	expected := `// Code generated by msgraph-types. DO NOT EDIT.
+
+export interface Group {
+  "@odata.type": "#microsoft.graph.group";
+  displayName?: string;
+}
+
+export interface TermStoreGroup {
+  "@odata.type": "#microsoft.graph.termStore.group";
+  scope?: string;
+}
+`
+	for _, roots := range [][]types.TypeBase{
+		{group, &groupCopy, termStoreGroup},
+		{termStoreGroup, &groupCopy, group},
+	} {
+		if out := Emit(roots); out != expected {
+			t.Errorf("expect:\n%s\nbut got:\n%s", expected, out)
+		}
+	}
+}
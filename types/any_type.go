@@ -11,14 +11,38 @@ func (t *AnyType) Validate(i interface{}, s string) []error {
 	return nil
 }
 
+func (t *AnyType) Diagnose(i interface{}, s string, options *ValidationOptions) Diagnostics {
+	return nil
+}
+
 func (t *AnyType) FilterReadOnlyFields(i interface{}) interface{} {
 	return i
 }
 
+func (t *AnyType) ApplyDefaults(i interface{}) interface{} {
+	return i
+}
+
 func (t *AnyType) FilterConfigurableFields(i interface{}) interface{} {
 	return i
 }
 
+func (t *AnyType) FilterWriteOnlyFields(i interface{}) interface{} {
+	return i
+}
+
+func (t *AnyType) MergeWriteOnlyFields(state interface{}, config interface{}) interface{} {
+	return state
+}
+
+func (t *AnyType) Redact(i interface{}) interface{} {
+	return i
+}
+
+func (t *AnyType) Sample(options *SampleOptions) interface{} {
+	return map[string]interface{}{}
+}
+
 func (t *AnyType) AsTypeBase() *TypeBase {
 	typeBase := TypeBase(t)
 	return &typeBase
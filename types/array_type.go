@@ -12,13 +12,18 @@ type ArrayType struct {
 	ItemType  *TypeReference `json:"itemType"`
 	MinLength *uint64        `json:"minLength"`
 	MaxLength *uint64        `json:"maxLength"`
+	Default   []interface{}  `json:"default"`
 }
 
 func (t *ArrayType) Validate(body interface{}, path string) []error {
-	if t == nil || body == nil {
-		return []error{}
+	return t.Diagnose(body, path, nil).Errors()
+}
+
+func (t *ArrayType) Diagnose(body interface{}, path string, options *ValidationOptions) Diagnostics {
+	if t == nil || body == nil || IsUnknown(body) {
+		return Diagnostics{}
 	}
-	errors := make([]error, 0)
+	diagnostics := make(Diagnostics, 0)
 	var itemType TypeBase
 	if t.ItemType != nil {
 		itemType = t.ItemType.Type
@@ -26,25 +31,41 @@ func (t *ArrayType) Validate(body interface{}, path string) []error {
 	// check body type
 	bodyArray, ok := body.([]interface{})
 	if !ok {
-		errors = append(errors, ErrorMismatch(path, "array", fmt.Sprintf("%T", body)))
-		return errors
+		return append(diagnostics, errorDiagnostics(path, ErrorMismatch(path, "array", fmt.Sprintf("%T", body)))...)
 	}
 
 	// check the length
 	if t.MinLength != nil && uint64(len(bodyArray)) < *t.MinLength {
-		errors = append(errors, ErrorCommon(path, fmt.Sprintf("array length is less than %d", *t.MinLength)))
+		diagnostics = append(diagnostics, errorDiagnostics(path, ErrorCommon(path, fmt.Sprintf("array length is less than %d", *t.MinLength)))...)
 	}
 
 	if t.MaxLength != nil && uint64(len(bodyArray)) > *t.MaxLength {
-		errors = append(errors, ErrorCommon(path, fmt.Sprintf("array length is greater than %d", *t.MaxLength)))
+		diagnostics = append(diagnostics, errorDiagnostics(path, ErrorCommon(path, fmt.Sprintf("array length is greater than %d", *t.MaxLength)))...)
 	}
 
 	for index, value := range bodyArray {
 		if itemType != nil {
-			errors = append(errors, itemType.Validate(value, path+"."+strconv.Itoa(index))...)
+			diagnostics = append(diagnostics, Diagnose(itemType, value, path+"."+strconv.Itoa(index), options)...)
 		}
 	}
-	return errors
+	return diagnostics
+}
+
+func (t *ArrayType) ApplyDefaults(i interface{}) interface{} {
+	if t == nil || i == nil || t.ItemType == nil || t.ItemType.Type == nil {
+		return i
+	}
+
+	bodyArray, ok := i.([]interface{})
+	if !ok {
+		return i
+	}
+
+	res := make([]interface{}, 0)
+	for _, value := range bodyArray {
+		res = append(res, ApplyDefaults(t.ItemType.Type, value))
+	}
+	return res
 }
 
 func (t *ArrayType) FilterReadOnlyFields(i interface{}) interface{} {
@@ -92,6 +113,86 @@ func (t *ArrayType) FilterConfigurableFields(i interface{}) interface{} {
 	return res
 }
 
+func (t *ArrayType) FilterWriteOnlyFields(i interface{}) interface{} {
+	if t == nil || i == nil || t.ItemType == nil || t.ItemType.Type == nil {
+		return i
+	}
+
+	bodyArray, ok := i.([]interface{})
+	if !ok {
+		return i
+	}
+
+	res := make([]interface{}, 0)
+	for _, value := range bodyArray {
+		res = append(res, FilterWriteOnlyFields(t.ItemType.Type, value))
+	}
+	return res
+}
+
+func (t *ArrayType) MergeWriteOnlyFields(state interface{}, config interface{}) interface{} {
+	if t == nil || state == nil || config == nil || t.ItemType == nil || t.ItemType.Type == nil {
+		return state
+	}
+
+	stateArray, ok := state.([]interface{})
+	if !ok {
+		return state
+	}
+	configArray, ok := config.([]interface{})
+	// the items can only be paired by index when the lengths are the same
+	if !ok || len(stateArray) != len(configArray) {
+		return state
+	}
+
+	res := make([]interface{}, 0)
+	for index, value := range stateArray {
+		res = append(res, MergeWriteOnlyFields(t.ItemType.Type, value, configArray[index]))
+	}
+	return res
+}
+
+func (t *ArrayType) Redact(i interface{}) interface{} {
+	if t == nil || i == nil || t.ItemType == nil || t.ItemType.Type == nil {
+		return i
+	}
+
+	bodyArray, ok := i.([]interface{})
+	if !ok {
+		return i
+	}
+
+	res := make([]interface{}, 0)
+	for _, value := range bodyArray {
+		res = append(res, Redact(t.ItemType.Type, value))
+	}
+	return res
+}
+
+func (t *ArrayType) Sample(options *SampleOptions) interface{} {
+	options = options.orDefault()
+	count := 0
+	if t.MinLength != nil {
+		count = int(*t.MinLength)
+	}
+	if options.isFull() {
+		count += 1 + options.intn(2)
+	}
+	if t.MaxLength != nil && count > int(*t.MaxLength) {
+		count = int(*t.MaxLength)
+	}
+
+	out := make([]interface{}, 0)
+	for i := 0; i < count; i++ {
+		if t.ItemType == nil || t.ItemType.Type == nil {
+			out = append(out, map[string]interface{}{})
+			continue
+		}
+		out = append(out, Sample(t.ItemType.Type, options.child()))
+	}
+	return out
+}
+
 func (t *ArrayType) AsTypeBase() *TypeBase {
 	typeBase := TypeBase(t)
 	return &typeBase
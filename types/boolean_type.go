@@ -3,21 +3,49 @@ package types
 var _ TypeBase = &BooleanType{}
 
 type BooleanType struct {
-	Type string `json:"$type"`
+	Type    string `json:"$type"`
+	Default *bool  `json:"default"`
 }
 
 func (t *BooleanType) Validate(i interface{}, s string) []error {
 	return nil
 }
 
+func (t *BooleanType) Diagnose(i interface{}, s string, options *ValidationOptions) Diagnostics {
+	return nil
+}
+
 func (t *BooleanType) FilterReadOnlyFields(i interface{}) interface{} {
 	return i
 }
 
+func (t *BooleanType) ApplyDefaults(i interface{}) interface{} {
+	return i
+}
+
 func (t *BooleanType) FilterConfigurableFields(i interface{}) interface{} {
 	return i
 }
 
+func (t *BooleanType) FilterWriteOnlyFields(i interface{}) interface{} {
+	return i
+}
+
+func (t *BooleanType) MergeWriteOnlyFields(state interface{}, config interface{}) interface{} {
+	return state
+}
+
+func (t *BooleanType) Redact(i interface{}) interface{} {
+	return i
+}
+
+func (t *BooleanType) Sample(options *SampleOptions) interface{} {
+	if t.Default != nil {
+		return *t.Default
+	}
+	return options.orDefault().intn(2) == 1
+}
+
 func (t *BooleanType) AsTypeBase() *TypeBase {
 	typeBase := TypeBase(t)
 	return &typeBase
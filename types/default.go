@@ -0,0 +1,129 @@
+package types
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// DefaultValue returns the default value of the type defined in the schema, it returns nil if there's no default value.
+func DefaultValue(t TypeBase) interface{} {
+	switch v := t.(type) {
+	case *StringType:
+		if v.Default != nil {
+			return *v.Default
+		}
+	case *NumberType:
+		if v.Default != nil {
+			return *v.Default
+		}
+	case *BooleanType:
+		if v.Default != nil {
+			return *v.Default
+		}
+	case *ObjectType:
+		if v.Default != nil {
+			return copyValue(v.Default)
+		}
+	case *ArrayType:
+		if v.Default != nil {
+			return copyValue(v.Default)
+		}
+	case *UnionType:
+		// the nullable complex properties are defined as anyOf the complex type and a nullable object
+		for _, element := range v.Elements {
+			if element == nil {
+				continue
+			}
+			if value := DefaultValue(element.Type); value != nil {
+				return value
+			}
+		}
+	}
+	return nil
+}
+
+// copyValue returns a deep copy of the JSON value, so that the default values shared by the types are never modified.
+func copyValue(input interface{}) interface{} {
+	switch v := input.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{})
+		for key, value := range v {
+			out[key] = copyValue(value)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, 0)
+		for _, value := range v {
+			out = append(out, copyValue(value))
+		}
+		return out
+	}
+	return input
+}
+
+// defaultString returns the default value of a string schema.
+func defaultString(input interface{}) *string {
+	if input == nil {
+		return nil
+	}
+	if v, ok := input.(string); ok {
+		return &v
+	}
+	log.Printf("[WARN] invalid default value for string type: %v", input)
+	return nil
+}
+
+// defaultNumber returns the default value of a number schema.
+func defaultNumber(input interface{}) *float64 {
+	switch v := input.(type) {
+	case nil:
+		return nil
+	case float64:
+		return &v
+	case int:
+		out := float64(v)
+		return &out
+	case json.Number:
+		if out, err := v.Float64(); err == nil {
+			return &out
+		}
+	}
+	log.Printf("[WARN] invalid default value for number type: %v", input)
+	return nil
+}
+
+// defaultBoolean returns the default value of a boolean schema.
+func defaultBoolean(input interface{}) *bool {
+	if input == nil {
+		return nil
+	}
+	if v, ok := input.(bool); ok {
+		return &v
+	}
+	log.Printf("[WARN] invalid default value for boolean type: %v", input)
+	return nil
+}
+
+// defaultArray returns the default value of an array schema.
+func defaultArray(input interface{}) []interface{} {
+	if input == nil {
+		return nil
+	}
+	if v, ok := input.([]interface{}); ok {
+		return v
+	}
+	log.Printf("[WARN] invalid default value for array type: %v", input)
+	return nil
+}
+
+// defaultObject returns the default value of an object schema.
+func defaultObject(input interface{}) map[string]interface{} {
+	if input == nil {
+		return nil
+	}
+	if v, ok := input.(map[string]interface{}); ok {
+		return v
+	}
+	log.Printf("[WARN] invalid default value for object type: %v", input)
+	return nil
+}
@@ -0,0 +1,111 @@
+package types
+
+import (
+	"container/list"
+	"sync"
+)
+
+// definitionCache is a LRU cache of the resource definitions and the type definitions, it's safe for concurrent use.
+// The definitions share the conversion cache of their api version, so only the definitions themselves are released.
+type definitionCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  *list.List
+	items    map[definitionKey]*list.Element
+	// generation is increased by the evictions, the definitions built before an eviction are not added after it
+	generation uint64
+}
+
+// definitionKey is the key of a resource definition if the url is specified, otherwise the key of a type definition.
+type definitionKey struct {
+	apiVersion string
+	url        string
+	typeName   string
+}
+
+type definitionEntry struct {
+	key definitionKey
+	// definition is a *ResourceType or a *TypeBase
+	definition interface{}
+}
+
+func newDefinitionCache(capacity int) *definitionCache {
+	return &definitionCache{
+		capacity: capacity,
+		entries:  list.New(),
+		items:    make(map[definitionKey]*list.Element),
+	}
+}
+
+func (c *definitionCache) get(key definitionKey) interface{} {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	element, ok := c.items[key]
+	if !ok {
+		return nil
+	}
+	c.entries.MoveToFront(element)
+	return element.Value.(*definitionEntry).definition
+}
+
+// currentGeneration returns the generation which is passed to add by the definitions built after it's called.
+func (c *definitionCache) currentGeneration() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.generation
+}
+
+// add adds the definition which is built in the generation, it's ignored if the cache is evicted since then, because
+// the definition may be built from the evicted schema.
+func (c *definitionCache) add(key definitionKey, definition interface{}, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if generation != c.generation {
+		return
+	}
+	if element, ok := c.items[key]; ok {
+		element.Value = &definitionEntry{key: key, definition: definition}
+		c.entries.MoveToFront(element)
+		return
+	}
+	c.items[key] = c.entries.PushFront(&definitionEntry{key: key, definition: definition})
+	for c.entries.Len() > c.capacity {
+		oldest := c.entries.Back()
+		c.entries.Remove(oldest)
+		delete(c.items, oldest.Value.(*definitionEntry).key)
+	}
+}
+
+// evict drops the definitions of the api version.
+func (c *definitionCache) evict(apiVersion string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+	for key, element := range c.items {
+		if key.apiVersion == apiVersion {
+			c.entries.Remove(element)
+			delete(c.items, key)
+		}
+	}
+}
+
+func (c *definitionCache) reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+	c.entries.Init()
+	c.items = make(map[definitionKey]*list.Element)
+}
+
+// usage returns the number of definitions of the api version.
+func (c *definitionCache) usage(apiVersion string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	definitions := 0
+	for key := range c.items {
+		if key.apiVersion == apiVersion {
+			definitions++
+		}
+	}
+	return definitions
+}
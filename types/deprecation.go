@@ -0,0 +1,77 @@
+package types
+
+// Deprecation describes a deprecated resource, property or enum value, it's populated from the `deprecated` field and
+// the `x-ms-deprecation` extension of the schema.
+type Deprecation struct {
+	Date        string `json:"date"`
+	RemovalDate string `json:"removalDate"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+}
+
+// DeprecationWarning reports a deprecated element which is used by a payload.
+type DeprecationWarning struct {
+	Path        string
+	Deprecation *Deprecation
+}
+
+func (w *DeprecationWarning) String() string {
+	return WarningDeprecated(w.Path, w.Deprecation).Error()
+}
+
+// CheckDeprecations returns the warnings of the deprecated properties and enum values used by the body, they're the
+// deprecation warnings reported by Diagnose.
+func CheckDeprecations(t TypeBase, body interface{}, path string) []*DeprecationWarning {
+	if t == nil || body == nil {
+		return nil
+	}
+	return Diagnose(t, body, path, nil).Deprecations()
+}
+
+// newDeprecation returns the deprecation of the schema or the operation, it returns nil if it's not deprecated.
+func newDeprecation(deprecated bool, extensions map[string]interface{}) *Deprecation {
+	input, ok := extensions["x-ms-deprecation"].(map[string]interface{})
+	if !ok {
+		if deprecated {
+			return &Deprecation{}
+		}
+		return nil
+	}
+	out := Deprecation{}
+	out.Date, _ = input["date"].(string)
+	out.RemovalDate, _ = input["removalDate"].(string)
+	out.Version, _ = input["version"].(string)
+	out.Description, _ = input["description"].(string)
+	return &out
+}
+
+// newEnumDeprecations returns the deprecations of the enum values defined in the `x-ms-enum` extension.
+func newEnumDeprecations(extensions map[string]interface{}) map[string]*Deprecation {
+	enum, ok := extensions["x-ms-enum"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	values, ok := enum["values"].([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make(map[string]*Deprecation)
+	for _, value := range values {
+		valueMap, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, ok := valueMap["value"].(string)
+		if !ok {
+			continue
+		}
+		deprecated, _ := valueMap["deprecated"].(bool)
+		if deprecation := newDeprecation(deprecated, valueMap); deprecation != nil {
+			out[name] = deprecation
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,119 @@
+package types
+
+import "strings"
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+
+	SeverityWarning
+
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "Error"
+	case SeverityWarning:
+		return "Warning"
+	case SeverityInfo:
+		return "Info"
+	}
+	return ""
+}
+
+// Diagnostic is an error or an advisory message reported by the validation.
+type Diagnostic struct {
+	Severity Severity
+	Path     string
+	Err      error
+	// Deprecation is set when the diagnostic reports a deprecated element
+	Deprecation *Deprecation
+}
+
+func (d Diagnostic) Error() string {
+	if d.Err == nil {
+		return ""
+	}
+	return d.Err.Error()
+}
+
+type Diagnostics []Diagnostic
+
+// Errors returns the errors in the diagnostics, it's the same as the result of Validate, which is nil if there are no
+// errors.
+func (d Diagnostics) Errors() []error {
+	var errors []error
+	for _, diagnostic := range d {
+		if diagnostic.Severity == SeverityError {
+			errors = append(errors, diagnostic.Err)
+		}
+	}
+	return errors
+}
+
+// Warnings returns the warnings in the diagnostics.
+func (d Diagnostics) Warnings() Diagnostics {
+	return d.BySeverity(SeverityWarning)
+}
+
+// Deprecations returns the warnings of the deprecated elements in the diagnostics.
+func (d Diagnostics) Deprecations() []*DeprecationWarning {
+	out := make([]*DeprecationWarning, 0)
+	for _, diagnostic := range d {
+		if diagnostic.Deprecation != nil {
+			out = append(out, &DeprecationWarning{Path: diagnostic.Path, Deprecation: diagnostic.Deprecation})
+		}
+	}
+	return out
+}
+
+func (d Diagnostics) BySeverity(severity Severity) Diagnostics {
+	out := make(Diagnostics, 0)
+	for _, diagnostic := range d {
+		if diagnostic.Severity == severity {
+			out = append(out, diagnostic)
+		}
+	}
+	return out
+}
+
+func (d Diagnostics) HasErrors() bool {
+	for _, diagnostic := range d {
+		if diagnostic.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (d Diagnostics) String() string {
+	lines := make([]string, 0)
+	for _, diagnostic := range d {
+		lines = append(lines, diagnostic.Severity.String()+": "+diagnostic.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func errorDiagnostics(path string, errors ...error) Diagnostics {
+	out := make(Diagnostics, 0)
+	for _, err := range errors {
+		out = append(out, Diagnostic{
+			Severity: SeverityError,
+			Path:     path,
+			Err:      err,
+		})
+	}
+	return out
+}
+
+func deprecationDiagnostic(path string, deprecation *Deprecation) Diagnostic {
+	return Diagnostic{
+		Severity:    SeverityWarning,
+		Path:        path,
+		Err:         WarningDeprecated(path, deprecation),
+		Deprecation: deprecation,
+	}
+}
@@ -39,6 +39,28 @@ func ErrorShouldDefine(key string) error {
 	return fmt.Errorf("`%s` is required, but no definition was found", strings.TrimPrefix(key, "."))
 }
 
+func WarningDeprecated(key string, deprecation *Deprecation) error {
+	message := fmt.Sprintf("`%s` is deprecated", strings.TrimPrefix(key, "."))
+	if key == "" {
+		message = "the resource is deprecated"
+	}
+	if deprecation != nil && deprecation.RemovalDate != "" {
+		message += fmt.Sprintf(" and will be removed on %s", deprecation.RemovalDate)
+	}
+	if deprecation != nil && deprecation.Description != "" {
+		message += ", " + deprecation.Description
+	}
+	return fmt.Errorf("%s", message)
+}
+
+func WarningInvalidPattern(key string, pattern string, err error) error {
+	return fmt.Errorf("`%s` is not validated, failed to match pattern %s: %v", strings.TrimPrefix(key, "."), pattern, err)
+}
+
+func InfoEvolvableEnumMember(key string, value string) error {
+	return fmt.Errorf("`%s`'s value `%s` is an evolvable enum member, it's only returned when the request has the `Prefer: include-unknown-enum-members` header", strings.TrimPrefix(key, "."), value)
+}
+
 func getSuggestion(value string, options []string) string {
 	suggestion := ""
 	distance := 1 << 16
@@ -0,0 +1,329 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// fuzzResource is a resource of the embedded documents whose bodies are fuzzed.
+type fuzzResource struct {
+	APIVersion string
+	Url        string
+	Definition *ResourceType
+}
+
+// fuzzUrls are the real Graph resources which are fuzzed, they cover the entities, the contained entities and the
+// references, and they're few enough that the seed corpus runs with the unit tests.
+var fuzzUrls = []string{
+	"/applications",
+	"/applications/{application-id}/extensionProperties",
+	"/applications/{application-id}/owners/$ref",
+	"/groups",
+	"/users",
+}
+
+// fuzzResources returns the fuzzed resources of the embedded documents, it fails the fuzz target if none of them is
+// found, because the indexes of the corpus address the resources.
+func fuzzResources(f *testing.F) []fuzzResource {
+	msgraphTypes := DefaultMSGraphSchemaLoader()
+	out := make([]fuzzResource, 0)
+	for _, apiVersion := range availableAPIVersions() {
+		for _, url := range fuzzUrls {
+			if definition := msgraphTypes.GetResourceDefinition(apiVersion, url); definition != nil && definition.Body != nil {
+				out = append(out, fuzzResource{APIVersion: apiVersion, Url: url, Definition: definition})
+			}
+		}
+	}
+	if len(out) == 0 {
+		f.Fatalf("failed to load the resources %v of the embedded documents", fuzzUrls)
+	}
+	return out
+}
+
+// fuzzCorpus generates the seed corpus, each entry is the index of the resource, the seed of the random values and
+// the number of mutations applied to the generated body.
+func fuzzCorpus(resources []fuzzResource) [][3]int64 {
+	out := make([][3]int64, 0)
+	for i := range resources {
+		for seed := int64(0); seed < 3; seed++ {
+			for _, mutations := range []int64{0, 1, 5} {
+				out = append(out, [3]int64{int64(i), seed, mutations})
+			}
+		}
+	}
+	return out
+}
+
+// fuzzBody returns a full sample body of the resource which is mutated, the body is round-tripped through JSON so that
+// it has the same types as the payloads decoded from the requests.
+func fuzzBody(resource fuzzResource, seed int64, mutations uint8) interface{} {
+	r := rand.New(rand.NewSource(seed))
+	body := resource.Definition.Sample(&SampleOptions{Mode: SampleFull, Rand: r})
+	for i := 0; i < int(mutations); i++ {
+		body = mutate(resource.Definition.Body.Type, body, r)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return body
+	}
+	var out interface{}
+	_ = json.Unmarshal(data, &out)
+	return out
+}
+
+// mutate returns a copy of the body with a random mutation, e.g. a read-only property is added, a property is removed,
+// or a value is replaced by a value of another type.
+func mutate(t TypeBase, body interface{}, r *rand.Rand) interface{} {
+	if union, ok := t.(*UnionType); ok {
+		t = union.matchElement(body)
+	}
+	switch v := body.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{})
+		keys := make([]string, 0)
+		for key, value := range v {
+			out[key] = value
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		objectType, _ := t.(*ObjectType)
+		switch r.Intn(4) {
+		case 0:
+			// add a property which is not generated, e.g. the read-only or the navigation properties
+			if objectType != nil && len(objectType.Properties) != 0 {
+				names := make([]string, 0)
+				for name := range objectType.Properties {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				name := names[r.Intn(len(names))]
+				if def := objectType.Properties[name]; def.Type != nil && def.Type.Type != nil {
+					out[name] = Sample(def.Type.Type, &SampleOptions{Mode: SampleFull, Rand: r, MaxDepth: 2})
+				}
+			}
+		case 1:
+			if len(keys) != 0 {
+				delete(out, keys[r.Intn(len(keys))])
+			}
+		case 2:
+			out[fmt.Sprintf("unknown%d", r.Intn(10))] = randomValue(r)
+		case 3:
+			if len(keys) != 0 {
+				key := keys[r.Intn(len(keys))]
+				var propertyType TypeBase
+				if objectType != nil {
+					if def, ok := objectType.Properties[key]; ok && def.Type != nil {
+						propertyType = def.Type.Type
+					}
+				}
+				if propertyType != nil && r.Intn(2) == 0 {
+					out[key] = mutate(propertyType, out[key], r)
+				} else {
+					out[key] = randomValue(r)
+				}
+			}
+		}
+		return out
+	case []interface{}:
+		out := append([]interface{}{}, v...)
+		var itemType TypeBase
+		if arrayType, ok := t.(*ArrayType); ok && arrayType.ItemType != nil {
+			itemType = arrayType.ItemType.Type
+		}
+		if len(out) != 0 && itemType != nil && r.Intn(2) == 0 {
+			i := r.Intn(len(out))
+			out[i] = mutate(itemType, out[i], r)
+		} else {
+			out = append(out, randomValue(r))
+		}
+		return out
+	}
+	return randomValue(r)
+}
+
+func randomValue(r *rand.Rand) interface{} {
+	switch r.Intn(6) {
+	case 0:
+		return nil
+	case 1:
+		return r.Intn(2) == 0
+	case 2:
+		return r.Float64() * 1000
+	case 3:
+		return randomString(r, r.Intn(8))
+	case 4:
+		return []interface{}{randomString(r, 4)}
+	}
+	return map[string]interface{}{"key": randomString(r, 4)}
+}
+
+// walkProperties calls the visit function for each property of the objects in the body. The values of the union types
+// are walked by the matched elements if matchUnions is true, otherwise they're skipped, because the filters of the
+// read-only and the configurable fields return them as they are.
+func walkProperties(t TypeBase, body interface{}, path string, matchUnions bool, visit func(path string, key string, def ObjectProperty, value interface{})) {
+	switch v := t.(type) {
+	case *UnionType:
+		if !matchUnions {
+			return
+		}
+		if element := v.matchElement(body); element != nil {
+			walkProperties(element, body, path, matchUnions, visit)
+		}
+	case *ArrayType:
+		if items, ok := body.([]interface{}); ok && v.ItemType != nil && v.ItemType.Type != nil {
+			for i, item := range items {
+				walkProperties(v.ItemType.Type, item, fmt.Sprintf("%s.%d", path, i), matchUnions, visit)
+			}
+		}
+	case *ObjectType:
+		bodyMap, ok := body.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for key, value := range bodyMap {
+			def, ok := v.Properties[key]
+			if !ok {
+				if v.AdditionalProperties != nil && v.AdditionalProperties.Type != nil {
+					walkProperties(v.AdditionalProperties.Type, value, path+"."+key, matchUnions, visit)
+				}
+				continue
+			}
+			visit(path, key, def, value)
+			if def.Type != nil && def.Type.Type != nil {
+				walkProperties(def.Type.Type, value, path+"."+key, matchUnions, visit)
+			}
+		}
+	}
+}
+
+// containsKeys returns true if the keys of the objects in the output are also in the input.
+func containsKeys(input interface{}, output interface{}) bool {
+	switch v := output.(type) {
+	case map[string]interface{}:
+		inputMap, ok := input.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range v {
+			if _, ok := inputMap[key]; !ok || !containsKeys(inputMap[key], value) {
+				return false
+			}
+		}
+	case []interface{}:
+		inputArray, ok := input.([]interface{})
+		if !ok || len(inputArray) < len(v) {
+			return false
+		}
+		for i, value := range v {
+			if !containsKeys(inputArray[i], value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func FuzzValidate(f *testing.F) {
+	resources := fuzzResources(f)
+	for _, entry := range fuzzCorpus(resources) {
+		f.Add(uint16(entry[0]), entry[1], uint8(entry[2]))
+	}
+
+	f.Fuzz(func(t *testing.T, index uint16, seed int64, mutations uint8) {
+		resource := resources[int(index)%len(resources)]
+		body := fuzzBody(resource, seed, mutations)
+
+		errs := resource.Definition.Validate(body, "")
+		if mutations == 0 && len(errs) != 0 {
+			t.Errorf("expect the sample of %s api-version %s to be valid but got %v: %v", resource.Url, resource.APIVersion, errs, body)
+		}
+		for _, options := range []*ValidationOptions{nil, {UnknownProperties: UnknownPropertyWarning, SkipRequired: true}} {
+			diagnostics := resource.Definition.Diagnose(body, "", options)
+			if options == nil && len(diagnostics.Errors()) != len(errs) {
+				t.Errorf("expect Diagnose to report the same errors as Validate but got %v and %v", diagnostics.Errors(), errs)
+			}
+		}
+		resource.Definition.ApplyDefaults(body)
+		resource.Definition.Redact(body)
+		CheckDeprecations(resource.Definition, body, "")
+	})
+}
+
+func FuzzValidateJSON(f *testing.F) {
+	resources := fuzzResources(f)
+	for _, entry := range fuzzCorpus(resources) {
+		data, _ := json.Marshal(fuzzBody(resources[entry[0]], entry[1], uint8(entry[2])))
+		f.Add(uint16(entry[0]), data)
+	}
+
+	f.Fuzz(func(t *testing.T, index uint16, data []byte) {
+		resource := resources[int(index)%len(resources)]
+		var body interface{}
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Skip()
+		}
+		resource.Definition.Validate(body, "")
+		resource.Definition.FilterConfigurableFields(body)
+		resource.Definition.FilterReadOnlyFields(body)
+		resource.Definition.FilterWriteOnlyFields(body)
+		resource.Definition.MergeWriteOnlyFields(body, body)
+	})
+}
+
+func FuzzFilterFields(f *testing.F) {
+	resources := fuzzResources(f)
+	for _, entry := range fuzzCorpus(resources) {
+		f.Add(uint16(entry[0]), entry[1], uint8(entry[2]))
+	}
+
+	f.Fuzz(func(t *testing.T, index uint16, seed int64, mutations uint8) {
+		resource := resources[int(index)%len(resources)]
+		bodyType := resource.Definition.Body.Type
+		body := fuzzBody(resource, seed, mutations)
+
+		configurable := bodyType.FilterConfigurableFields(body)
+		walkProperties(bodyType, configurable, "", false, func(path string, key string, def ObjectProperty, value interface{}) {
+			if !def.IsRequired() && (def.IsReadOnly() || def.IsDeployTimeConstant()) {
+				t.Errorf("expect the configurable fields of %s not to contain %s.%s: %v", resource.Url, path, key, configurable)
+			}
+		})
+		if again := bodyType.FilterConfigurableFields(configurable); !reflect.DeepEqual(again, configurable) {
+			t.Errorf("expect FilterConfigurableFields of %s to be idempotent but got %v and %v", resource.Url, configurable, again)
+		}
+		if !containsKeys(body, configurable) {
+			t.Errorf("expect the configurable fields of %s to be in the body but got %v: %v", resource.Url, configurable, body)
+		}
+
+		readOnly := bodyType.FilterReadOnlyFields(body)
+		walkProperties(bodyType, readOnly, "", false, func(path string, key string, def ObjectProperty, value interface{}) {
+			// the objects and the arrays are kept if they contain read-only fields
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				return
+			}
+			if !def.IsReadOnly() {
+				t.Errorf("expect the read-only fields of %s to contain only the read-only fields but got %s.%s: %v", resource.Url, path, key, readOnly)
+			}
+		})
+		if again := bodyType.FilterReadOnlyFields(readOnly); !reflect.DeepEqual(again, readOnly) {
+			t.Errorf("expect FilterReadOnlyFields of %s to be idempotent but got %v and %v", resource.Url, readOnly, again)
+		}
+		if !containsKeys(body, readOnly) {
+			t.Errorf("expect the read-only fields of %s to be in the body but got %v: %v", resource.Url, readOnly, body)
+		}
+
+		state := FilterWriteOnlyFields(bodyType, body)
+		walkProperties(bodyType, state, "", true, func(path string, key string, def ObjectProperty, value interface{}) {
+			if def.IsWriteOnly() {
+				t.Errorf("expect the state of %s not to contain %s.%s: %v", resource.Url, path, key, state)
+			}
+		})
+		if again := FilterWriteOnlyFields(bodyType, state); !reflect.DeepEqual(again, state) {
+			t.Errorf("expect FilterWriteOnlyFields of %s to be idempotent but got %v and %v", resource.Url, state, again)
+		}
+	})
+}
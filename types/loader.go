@@ -1,8 +1,10 @@
 package types
 
 import (
-	"embed"
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"sort"
 	"strings"
@@ -12,141 +14,356 @@ import (
 	typesEmbed "github.com/ms-henglu/go-msgraph-types/embed"
 )
 
-func DefaultMSGraphSchemaLoader() *MSGraphSchemaLoader {
-	return &MSGraphSchemaLoader{
-		staticFiles: typesEmbed.StaticFiles,
-		mutex:       sync.Mutex{},
-		cache:       make(map[*openapi3.Schema]*TypeBase),
-	}
+func DefaultMSGraphSchemaLoader(options ...LoaderOption) *MSGraphSchemaLoader {
+	return NewMSGraphSchemaLoader(typesEmbed.StaticFiles, options...)
 }
 
-func NewMSGraphSchemaLoader(staticFiles embed.FS) *MSGraphSchemaLoader {
-	return &MSGraphSchemaLoader{
-		staticFiles: staticFiles,
-		mutex:       sync.Mutex{},
-		cache:       make(map[*openapi3.Schema]*TypeBase),
+// NewMSGraphSchemaLoader returns a loader which reads the schemas from `openapi/<api-version>/openapi.yaml` of the
+// file system, e.g. an embedded file system or a directory returned by os.DirFS.
+func NewMSGraphSchemaLoader(staticFiles fs.FS, options ...LoaderOption) *MSGraphSchemaLoader {
+	out := &MSGraphSchemaLoader{
+		staticFiles:    staticFiles,
+		mutex:          sync.Mutex{},
+		caches:         make(map[string]*typeCache),
+		sensitiveRules: DefaultSensitivePropertyRules(),
+	}
+	for _, option := range options {
+		option(out)
 	}
+	return out
 }
 
 type MSGraphSchemaLoader struct {
-	schemaMap   map[string]*openapi3.T
+	// schemaMap contains the schemas which are loaded or being loaded, the mutex only guards the map itself,
+	// so that loading one api version doesn't block the others.
+	schemaMap   map[string]*schemaEntry
 	mutex       sync.Mutex
-	staticFiles embed.FS
-	cache       map[*openapi3.Schema]*TypeBase
+	staticFiles fs.FS
+	// caches contains the conversion caches of the api versions, they're guarded by the mutex.
+	caches map[string]*typeCache
+	// sensitiveRules detect the sensitive properties when the types are converted.
+	sensitiveRules []SensitivePropertyRule
+	// dropDocuments drops the parsed documents once they're indexed.
+	dropDocuments bool
+	// definitions caches the resource definitions, it's nil if they're built on every request.
+	definitions *definitionCache
+	// maxConvertedTypes bounds the conversion cache of each api version, it's unbounded if it's 0.
+	maxConvertedTypes int
+	// permissions are shared by all api versions, they're loaded once successfully, they're guarded by the
+	// permissionsMutex.
+	permissionsMutex  sync.Mutex
+	permissionsLoaded bool
+	permissions       permissionIndex
+}
+
+// schemaEntry is the schema of an api version, it's loaded only once no matter how many callers request it concurrently.
+type schemaEntry struct {
+	done  chan struct{}
+	doc   *openapi3.T
+	index *schemaIndex
+	size  int
+	err   error
+	// permissionsErr is the error of loading the permissions, the schema is loaded without them.
+	permissionsErr error
+}
+
+// MemoryUsage describes the memory retained by the loader for an api version.
+type MemoryUsage struct {
+	APIVersion string
+	// SchemaBytes is the size of the OpenAPI document.
+	SchemaBytes int
+	// DocumentRetained is true if the parsed OpenAPI document is kept in memory.
+	DocumentRetained bool
+	// Paths is the number of indexed paths.
+	Paths int
+	// Schemas is the number of the schemas of the type definitions, they're kept even if the document is dropped
+	// because the types are converted from them.
+	Schemas int
+	// Definitions is the number of cached resource definitions and type definitions.
+	Definitions int
+	// ConvertedTypes is the number of converted schemas kept by the conversion cache.
+	ConvertedTypes int
 }
 
 func (r *MSGraphSchemaLoader) GetSchema(apiVersion string) *openapi3.T {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-	if r.schemaMap == nil {
-		r.schemaMap = make(map[string]*openapi3.T)
+	doc, err := r.GetSchemaWithContext(context.Background(), apiVersion)
+	if err != nil {
+		log.Printf("[ERROR] %+v", err)
+		return nil
 	}
-	if _, ok := r.schemaMap[apiVersion]; !ok {
-		data, err := r.staticFiles.ReadFile(fmt.Sprintf("openapi/%s/openapi.yaml", apiVersion))
-		if err != nil {
-			log.Printf("[ERROR] failed to read schema: %+v", err)
-			return nil
-		}
+	return doc
+}
 
-		doc, err := openapi3.NewLoader().LoadFromData(data)
-		if err != nil {
-			log.Printf("[ERROR] failed to parse schema: %+v", err)
-			return nil
-		}
-		r.schemaMap[apiVersion] = doc
+// GetSchemaWithContext returns the schema of the api version, it's loaded on the first request.
+// When the context is done, it returns the context's error without waiting for the loading, which continues in
+// the background, so that the following requests can use the loaded schema.
+// If the documents are dropped, the schema is parsed again on every call and the returned document isn't kept by the
+// loader, so the callers which need it repeatedly should keep it.
+func (r *MSGraphSchemaLoader) GetSchemaWithContext(ctx context.Context, apiVersion string) (*openapi3.T, error) {
+	entry, err := r.getSchemaEntry(ctx, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	if entry.doc != nil {
+		return entry.doc, nil
 	}
-	return r.schemaMap[apiVersion]
+	doc, _, err := r.parseSchema(apiVersion)
+	return doc, err
 }
 
-func (r *MSGraphSchemaLoader) ListResources(apiVersion string) []ResourceType {
-	schema := r.GetSchema(apiVersion)
-	if schema == nil || schema.Paths == nil {
-		return nil
+// Preload loads the schemas of the api versions in parallel, it loads all available api versions if none is specified.
+func (r *MSGraphSchemaLoader) Preload(ctx context.Context, apiVersions ...string) error {
+	if len(apiVersions) == 0 {
+		apiVersions = r.ListAPIVersions()
 	}
 
-	var resources []ResourceType
+	errs := make([]error, len(apiVersions))
+	wg := sync.WaitGroup{}
+	for i, apiVersion := range apiVersions {
+		wg.Add(1)
+		go func(i int, apiVersion string) {
+			defer wg.Done()
+			_, errs[i] = r.getSchemaEntry(ctx, apiVersion)
+		}(i, apiVersion)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
 
-	urlMap := make(map[string]string)
-	m := make(map[string]*openapi3.PathItem)
+// EvictSchema drops the schema and the converted types of the api version, they're loaded again on the next request.
+func (r *MSGraphSchemaLoader) EvictSchema(apiVersion string) {
+	r.mutex.Lock()
+	delete(r.schemaMap, apiVersion)
+	delete(r.caches, apiVersion)
+	r.mutex.Unlock()
+	if r.definitions != nil {
+		r.definitions.evict(apiVersion)
+	}
+}
 
-	for path, pathItem := range schema.Paths.Map() {
-		normalizedPath, _, _ := normalizeTemplatedPath(path)
-		m[normalizedPath] = pathItem
-		urlMap[normalizedPath] = path
+// MemoryUsage returns the memory retained by the loader for the loaded api versions, sorted by api version.
+func (r *MSGraphSchemaLoader) MemoryUsage() []MemoryUsage {
+	r.mutex.Lock()
+	entries := make(map[string]*schemaEntry)
+	for apiVersion, entry := range r.schemaMap {
+		entries[apiVersion] = entry
+	}
+	caches := make(map[string]*typeCache)
+	for apiVersion, cache := range r.caches {
+		caches[apiVersion] = cache
 	}
+	r.mutex.Unlock()
 
-	for path, pathItem := range m {
-		if pathItem.Post == nil {
+	out := make([]MemoryUsage, 0)
+	for apiVersion, entry := range entries {
+		select {
+		case <-entry.done:
+		default:
 			continue
 		}
-
-		if !strings.Contains(path, "/$ref") {
-			itemPathItem := m[fmt.Sprintf("%s/%s", path, "{}")]
-			if itemPathItem == nil {
-				continue
-			}
-
-			if itemPathItem.Get == nil || itemPathItem.Delete == nil {
-				continue
-			}
+		if entry.err != nil {
+			continue
+		}
+		usage := MemoryUsage{
+			APIVersion:       apiVersion,
+			SchemaBytes:      entry.size,
+			DocumentRetained: entry.doc != nil,
+			Paths:            len(entry.index.paths),
+			Schemas:          len(entry.index.schemas),
+		}
+		if cache := caches[apiVersion]; cache != nil {
+			usage.ConvertedTypes = cache.stats(apiVersion).Entries
 		}
+		if r.definitions != nil {
+			usage.Definitions = r.definitions.usage(apiVersion)
+		}
+		out = append(out, usage)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].APIVersion < out[j].APIVersion
+	})
+	return out
+}
 
-		resourceType := ResourceType{
-			Type:        "resource",
-			Url:         urlMap[path],
-			Name:        pathItem.Post.Summary,
-			Description: pathItem.Post.Description,
+func (r *MSGraphSchemaLoader) getSchemaEntry(ctx context.Context, apiVersion string) (*schemaEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	if r.schemaMap == nil {
+		r.schemaMap = make(map[string]*schemaEntry)
+	}
+	entry, ok := r.schemaMap[apiVersion]
+	if !ok {
+		entry = &schemaEntry{
+			done: make(chan struct{}),
 		}
+		r.schemaMap[apiVersion] = entry
+		go r.loadSchema(apiVersion, entry)
+	}
+	r.mutex.Unlock()
 
-		if pathItem.Post.ExternalDocs != nil {
-			resourceType.ExternalDocs = &ExternalDocumentation{
-				Description: pathItem.Post.ExternalDocs.Description,
-				Url:         pathItem.Post.ExternalDocs.URL,
-			}
+	select {
+	case <-entry.done:
+		if entry.err != nil {
+			return nil, entry.err
 		}
+		return entry, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
 
-		resources = append(resources, resourceType)
+// getSchemaIndex returns the index of the schema, it returns nil if the schema can't be loaded.
+func (r *MSGraphSchemaLoader) getSchemaIndex(apiVersion string) *schemaIndex {
+	entry, err := r.getSchemaEntry(context.Background(), apiVersion)
+	if err != nil {
+		log.Printf("[ERROR] %+v", err)
+		return nil
 	}
+	return entry.index
+}
 
-	sort.Slice(resources, func(i, j int) bool {
-		return resources[i].Name < resources[j].Name
-	})
-	return resources
+func (r *MSGraphSchemaLoader) parseSchema(apiVersion string) (*openapi3.T, int, error) {
+	data, err := fs.ReadFile(r.staticFiles, fmt.Sprintf("openapi/%s/openapi.yaml", apiVersion))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read schema: %+v", err)
+	}
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse schema: %+v", err)
+	}
+	return doc, len(data), nil
 }
 
-func (r *MSGraphSchemaLoader) ListReadableResources(apiVersion string) []ResourceType {
-	schema := r.GetSchema(apiVersion)
-	if schema == nil || schema.Paths == nil {
-		return nil
+// loadPermissions returns the permissions, they're optional, so the schema is still loaded if they can't be loaded,
+// and the failed loading is retried by the next schema loading.
+func (r *MSGraphSchemaLoader) loadPermissions() (permissionIndex, error) {
+	r.permissionsMutex.Lock()
+	defer r.permissionsMutex.Unlock()
+	if r.permissionsLoaded {
+		return r.permissions, nil
+	}
+	permissions, err := loadPermissions(r.staticFiles)
+	if err != nil {
+		log.Printf("[WARN] the permissions are not available: %+v", err)
+		return nil, err
 	}
+	r.permissions, r.permissionsLoaded = permissions, true
+	return r.permissions, nil
+}
 
-	var resources []ResourceType
+func (r *MSGraphSchemaLoader) loadSchema(apiVersion string, entry *schemaEntry) {
+	defer close(entry.done)
 
-	for path, pathItem := range schema.Paths.Map() {
-		if pathItem.Get == nil {
-			continue
-		}
-		resourceType := ResourceType{
-			Type:        "resource",
-			Url:         path,
-			Name:        pathItem.Get.Summary,
-			Description: pathItem.Get.Description,
-		}
+	entry.doc, entry.size, entry.err = r.parseSchema(apiVersion)
+	var permissions permissionIndex
+	if entry.err == nil {
+		permissions, entry.permissionsErr = r.loadPermissions()
+	}
 
-		if pathItem.Get.ExternalDocs != nil {
-			resourceType.ExternalDocs = &ExternalDocumentation{
-				Description: pathItem.Get.ExternalDocs.Description,
-				Url:         pathItem.Get.ExternalDocs.URL,
-			}
+	// the failed loading is not cached, so that it can be retried
+	if entry.err != nil {
+		entry.doc = nil
+		r.mutex.Lock()
+		if r.schemaMap[apiVersion] == entry {
+			delete(r.schemaMap, apiVersion)
 		}
+		r.mutex.Unlock()
+		return
+	}
 
-		resources = append(resources, resourceType)
+	entry.index = newSchemaIndex(entry.doc, permissions)
+	if r.dropDocuments {
+		entry.doc = nil
 	}
+}
 
-	sort.Slice(resources, func(i, j int) bool {
-		return resources[i].Name < resources[j].Name
+func (r *MSGraphSchemaLoader) typeCache(apiVersion string) *typeCache {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.caches == nil {
+		r.caches = make(map[string]*typeCache)
+	}
+	if r.caches[apiVersion] == nil {
+		r.caches[apiVersion] = newTypeCache(r.sensitiveRules, r.maxConvertedTypes)
+	}
+	return r.caches[apiVersion]
+}
+
+// CacheStats returns the statistics of the conversion caches, sorted by api version.
+func (r *MSGraphSchemaLoader) CacheStats() []CacheStats {
+	r.mutex.Lock()
+	caches := make(map[string]*typeCache)
+	for apiVersion, cache := range r.caches {
+		caches[apiVersion] = cache
+	}
+	r.mutex.Unlock()
+
+	out := make([]CacheStats, 0)
+	for apiVersion, cache := range caches {
+		out = append(out, cache.stats(apiVersion))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].APIVersion < out[j].APIVersion
 	})
-	return resources
+	return out
+}
+
+// EvictCache drops the converted types of the api version, the definitions returned before are still valid.
+func (r *MSGraphSchemaLoader) EvictCache(apiVersion string) {
+	r.mutex.Lock()
+	cache := r.caches[apiVersion]
+	r.mutex.Unlock()
+	if cache != nil {
+		cache.reset()
+	}
+	if r.definitions != nil {
+		r.definitions.evict(apiVersion)
+	}
+}
+
+// ResetCache drops the converted types of all api versions.
+func (r *MSGraphSchemaLoader) ResetCache() {
+	r.mutex.Lock()
+	caches := make([]*typeCache, 0)
+	for _, cache := range r.caches {
+		caches = append(caches, cache)
+	}
+	r.mutex.Unlock()
+	for _, cache := range caches {
+		cache.reset()
+	}
+	if r.definitions != nil {
+		r.definitions.reset()
+	}
+}
+
+func (r *MSGraphSchemaLoader) ListResources(apiVersion string) []ResourceType {
+	index := r.getSchemaIndex(apiVersion)
+	if index == nil {
+		return nil
+	}
+	return copyResourceTypes(index.resources)
+}
+
+func (r *MSGraphSchemaLoader) ListReadableResources(apiVersion string) []ResourceType {
+	index := r.getSchemaIndex(apiVersion)
+	if index == nil {
+		return nil
+	}
+	return copyResourceTypes(index.readableResources)
+}
+
+// copyResourceTypes returns the copies of the resources, whose slices and maps are not shared with the index, so that
+// the callers can modify them.
+func copyResourceTypes(input []ResourceType) []ResourceType {
+	out := make([]ResourceType, 0, len(input))
+	for _, resource := range input {
+		out = append(out, resource.copy())
+	}
+	return out
 }
 
 func (r *MSGraphSchemaLoader) ListAPIVersions() []string {
@@ -154,74 +371,127 @@ func (r *MSGraphSchemaLoader) ListAPIVersions() []string {
 }
 
 func (r *MSGraphSchemaLoader) GetResourceDefinition(apiVersion, url string) *ResourceType {
-	schema := r.GetSchema(apiVersion)
-	if schema == nil {
-		return nil
+	key := definitionKey{apiVersion: apiVersion, url: url}
+	var generation uint64
+	if r.definitions != nil {
+		generation = r.definitions.currentGeneration()
+		if definition, ok := r.definitions.get(key).(*ResourceType); ok {
+			out := definition.copy()
+			return &out
+		}
 	}
+	cache := r.typeCache(apiVersion)
 
-	postOperation := findOperation(schema, url, "POST")
-	if postOperation == nil || postOperation.RequestBody == nil || postOperation.RequestBody.Value == nil || postOperation.RequestBody.Value.Content == nil {
+	index := r.getSchemaIndex(apiVersion)
+	if index == nil {
 		return nil
 	}
 
-	content := postOperation.RequestBody.Value.Content.Get("application/json")
-	if content == nil || content.Schema == nil {
+	item := index.find(url)
+	if item == nil || item.post == nil || item.post.requestBody == nil {
 		return nil
 	}
 
-	requestBodyType := NewTypeBaseFromOpenAPISchema(content.Schema.Value, r.cache)
+	requestBodyType := cache.convert(item.post.requestBody)
 	if requestBodyType == nil {
 		return nil
 	}
+	requestBodyType = withResourcePropertyFlags(index, url, *requestBodyType, cache).AsTypeBase()
 
-	out := ResourceType{
-		Type:        "resource",
-		Url:         url,
-		Name:        postOperation.Summary,
-		Description: postOperation.Description,
-		Body: &TypeReference{
-			Type: *requestBodyType,
-		},
+	kind := ResourceKindCollection
+	if strings.Contains(url, "/$ref") {
+		kind = ResourceKindReference
 	}
-
-	if postOperation.ExternalDocs != nil {
-		out.ExternalDocs = &ExternalDocumentation{
-			Description: postOperation.ExternalDocs.Description,
-			Url:         postOperation.ExternalDocs.URL,
-		}
+	out := index.newResourceType(url, kind, item.post)
+	out.Body = &TypeReference{
+		Type: *requestBodyType,
+	}
+	out.Resolver = &schemaReferenceResolver{
+		index:       index,
+		cache:       cache,
+		apiVersion:  apiVersion,
+		apiVersions: r.ListAPIVersions(),
 	}
 
+	if r.definitions != nil {
+		definition := out.copy()
+		r.definitions.add(key, &definition, generation)
+	}
 	return &out
 }
 
-func findOperation(doc *openapi3.T, url string, method string) *openapi3.Operation {
+func (s *schemaIndex) newResourceType(url string, kind ResourceKind, operation *operationIndex) ResourceType {
+	return ResourceType{
+		Type:         "resource",
+		Kind:         kind,
+		Url:          url,
+		Name:         operation.summary,
+		Description:  operation.description,
+		ExternalDocs: operation.externalDocs,
+		Tags:         append([]string(nil), operation.tags...),
+		EntityType:   operation.entityType,
+		Deprecated:   operation.deprecated,
+		Permissions:  s.permissions.resourcePermissions(url, kind, s),
+	}
+}
+
+// withResourcePropertyFlags returns a copy of the request body type whose properties are flagged with the semantics
+// derived from the paths of the resource:
+// 1. Identifier: the alternate keys, e.g. `appId` of `/applications(appId='{appId}')`.
+// 2. CreateOnly: the properties which are accepted by the POST operation but not the PATCH operation. Nothing is flagged
+// if the item path doesn't define the PATCH operation, and the navigation properties are never flagged.
+func withResourcePropertyFlags(index *schemaIndex, url string, input TypeBase, cache *typeCache) TypeBase {
+	objectType, ok := input.(*ObjectType)
+	if !ok {
+		return input
+	}
 	if !strings.HasPrefix(url, "/") {
 		url = "/" + url
 	}
-	pathItem := doc.Paths.Find(url)
-	if pathItem == nil {
-		return nil
+	normalizedUrl, _, _ := normalizeTemplatedPath(url)
+
+	flagsMap := make(map[string][]ObjectPropertyFlag)
+	for _, key := range index.alternateKeys[normalizedUrl] {
+		flagsMap[key] = append(flagsMap[key], Identifier)
+	}
+	if item := index.normalizedPaths[normalizedUrl+"/{}"]; item != nil && item.patch != nil && item.patch.requestBody != nil {
+		var patchObjectType *ObjectType
+		if patchType := cache.convert(item.patch.requestBody); patchType != nil {
+			patchObjectType, _ = (*patchType).(*ObjectType)
+		}
+		if patchObjectType != nil {
+			for key, def := range objectType.Properties {
+				if def.IsReadOnly() || def.IsIdentifier() || hasObjectPropertyFlag(flagsMap[key], Identifier) || def.IsNavigation() || IsInstanceAnnotation(key) {
+					continue
+				}
+				if _, ok := patchObjectType.Properties[key]; !ok {
+					flagsMap[key] = append(flagsMap[key], CreateOnly)
+				}
+			}
+		}
+	}
+	if len(flagsMap) == 0 {
+		return input
 	}
 
-	var operation *openapi3.Operation
-	switch method {
-	case "GET":
-		operation = pathItem.Get
-	case "POST":
-		operation = pathItem.Post
-	case "PUT":
-		operation = pathItem.Put
-	case "DELETE":
-		operation = pathItem.Delete
-	case "PATCH":
-		operation = pathItem.Patch
-	case "OPTIONS":
-		operation = pathItem.Options
-	case "HEAD":
-		operation = pathItem.Head
-	default:
-		return nil
+	out := *objectType
+	out.Properties = make(map[string]ObjectProperty)
+	for key, def := range objectType.Properties {
+		for _, flag := range flagsMap[key] {
+			if !hasObjectPropertyFlag(def.Flags, flag) {
+				def.Flags = append(append(make([]ObjectPropertyFlag, 0), def.Flags...), flag)
+			}
+		}
+		out.Properties[key] = def
 	}
+	return &out
+}
 
-	return operation
+func hasObjectPropertyFlag(flags []ObjectPropertyFlag, flag ObjectPropertyFlag) bool {
+	for _, value := range flags {
+		if value == flag {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,50 @@
+package types
+
+// LoaderOption customizes the MSGraphSchemaLoader.
+type LoaderOption func(*MSGraphSchemaLoader)
+
+// WithDocumentsDropped drops the parsed OpenAPI documents once they're indexed. The index keeps the summaries of the
+// operations and the schemas of the request bodies, the responses and the components, because the types are
+// converted from them, so only the other parts like the parameters, the examples and the non-json contents are
+// released. GetSchema still works, but it parses the document again on every call and doesn't keep it.
+func WithDocumentsDropped() LoaderOption {
+	return func(r *MSGraphSchemaLoader) {
+		r.dropDocuments = true
+	}
+}
+
+// WithMaxDefinitions caches at most max resource definitions and type definitions, the least recently used
+// definitions are dropped when the bound is exceeded and they're built again on the next request. The definitions are
+// built on every request by default. The types of the definitions are converted by the conversion cache of the api
+// version, which is bounded by WithMaxConvertedTypes.
+func WithMaxDefinitions(max int) LoaderOption {
+	return func(r *MSGraphSchemaLoader) {
+		if max <= 0 {
+			r.definitions = nil
+			return
+		}
+		r.definitions = newDefinitionCache(max)
+	}
+}
+
+// WithMaxConvertedTypes keeps at most max converted types in the conversion cache of each api version, the least
+// recently used types are evicted when a conversion exceeds the bound, and they're converted again on the next request.
+// The evicted types are released once the definitions returned before are released, they're not shared with the
+// types converted again. The conversion cache keeps one type for each converted schema by default.
+func WithMaxConvertedTypes(max int) LoaderOption {
+	return func(r *MSGraphSchemaLoader) {
+		if max < 0 {
+			max = 0
+		}
+		r.maxConvertedTypes = max
+	}
+}
+
+// WithSensitivePropertyRules replaces DefaultSensitivePropertyRules, which detect the sensitive properties by their
+// names when the schema doesn't specify whether the property is sensitive. The rules are evaluated in order and the
+// first matched rule wins.
+func WithSensitivePropertyRules(rules ...SensitivePropertyRule) LoaderOption {
+	return func(r *MSGraphSchemaLoader) {
+		r.sensitiveRules = append([]SensitivePropertyRule(nil), rules...)
+	}
+}
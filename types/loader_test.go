@@ -1,9 +1,21 @@
 package types
 
 import (
+	"context"
 	"log"
+	"math/rand"
+	"os"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
+	"unicode"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/ms-henglu/go-msgraph-types/embed"
 )
 
@@ -29,6 +41,73 @@ func Test_NewMSGraphSchemaLoader(t *testing.T) {
 	}
 }
 
+func Test_GetSchemaConcurrently(t *testing.T) {
+	msgraphTypes := DefaultMSGraphSchemaLoader()
+
+	const concurrency = 50
+	results := make(map[string][]*openapi3.T)
+	mutex := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		for _, version := range availableAPIVersions() {
+			wg.Add(1)
+			go func(version string) {
+				defer wg.Done()
+				schema := msgraphTypes.GetSchema(version)
+				mutex.Lock()
+				results[version] = append(results[version], schema)
+				mutex.Unlock()
+			}(version)
+		}
+	}
+	wg.Wait()
+
+	for _, version := range availableAPIVersions() {
+		if len(results[version]) != concurrency {
+			t.Fatalf("expect %d results but got %d for version %s", concurrency, len(results[version]), version)
+		}
+		for _, schema := range results[version] {
+			if schema == nil || schema != results[version][0] {
+				t.Errorf("expect the same schema to be shared by all callers for version %s", version)
+			}
+		}
+	}
+}
+
+func Test_GetSchemaWithContext(t *testing.T) {
+	msgraphTypes := DefaultMSGraphSchemaLoader()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := msgraphTypes.GetSchemaWithContext(ctx, "v1.0"); err == nil {
+		t.Errorf("expect an error when the context is canceled")
+	}
+
+	schema, err := msgraphTypes.GetSchemaWithContext(context.Background(), "v1.0")
+	if err != nil || schema == nil {
+		t.Errorf("failed to load schema version %s: %v", "v1.0", err)
+	}
+
+	if _, err := msgraphTypes.GetSchemaWithContext(context.Background(), "unknown"); err == nil {
+		t.Errorf("expect an error for an unknown version")
+	}
+}
+
+func Test_Preload(t *testing.T) {
+	msgraphTypes := DefaultMSGraphSchemaLoader()
+	if err := msgraphTypes.Preload(context.Background()); err != nil {
+		t.Fatalf("failed to preload schemas: %v", err)
+	}
+	for _, version := range availableAPIVersions() {
+		if msgraphTypes.GetSchema(version) == nil {
+			t.Errorf("failed to load schema version %s", version)
+		}
+	}
+	if err := msgraphTypes.Preload(context.Background(), "v1.0", "unknown"); err == nil {
+		t.Errorf("expect an error for an unknown version")
+	}
+}
+
 func Test_ListResources(t *testing.T) {
 	msgraphTypes := DefaultMSGraphSchemaLoader()
 	for _, version := range availableAPIVersions() {
@@ -51,6 +130,53 @@ func Test_ListReadableResources(t *testing.T) {
 	}
 }
 
+func Test_ListResourcesCopies(t *testing.T) {
+	msgraphTypes := NewMSGraphSchemaLoader(os.DirFS("testdata"), WithMaxDefinitions(2))
+	lists := map[string]func() []ResourceType{
+		"ListResources": func() []ResourceType {
+			return msgraphTypes.ListResources("v1.0")
+		},
+		"GetResourceDefinition": func() []ResourceType {
+			return []ResourceType{*msgraphTypes.GetResourceDefinition("v1.0", "/widgets")}
+		},
+		"ListReadableResources": func() []ResourceType {
+			return msgraphTypes.ListReadableResources("v1.0")
+		},
+		"QueryResources": func() []ResourceType {
+			return msgraphTypes.QueryResources("v1.0", ResourceQuery{})
+		},
+	}
+
+	for name, list := range lists {
+		resources := list()
+		if len(resources) == 0 || len(resources[0].Tags) == 0 {
+			t.Fatalf("expect %s to return the resources with tags but got %v", name, resources)
+		}
+		expected := resources[0].Tags[0]
+		resources[0].Tags[0] = "modified"
+		if actual := list()[0].Tags[0]; actual != expected {
+			t.Errorf("expect %s to return a copy of the tags %s but got %s", name, expected, actual)
+		}
+	}
+}
+
+func Test_DefinitionCacheEviction(t *testing.T) {
+	cache := newDefinitionCache(2)
+	key := definitionKey{apiVersion: "v1.0", url: "/widgets"}
+
+	generation := cache.currentGeneration()
+	cache.evict("v1.0")
+	cache.add(key, &ResourceType{Url: "/widgets"}, generation)
+	if cache.get(key) != nil {
+		t.Errorf("expect the definition built before the eviction not to be added")
+	}
+
+	cache.add(key, &ResourceType{Url: "/widgets"}, cache.currentGeneration())
+	if cache.get(key) == nil {
+		t.Errorf("expect the definition built after the eviction to be added")
+	}
+}
+
 func Test_ListAPIVersions(t *testing.T) {
 	msgraphTypes := DefaultMSGraphSchemaLoader()
 	actual := msgraphTypes.ListAPIVersions()
@@ -82,6 +208,572 @@ func Test_GetResourceDefinition(t *testing.T) {
 	}
 }
 
+func Test_GetResourceDefinitionConcurrently(t *testing.T) {
+	msgraphTypes := DefaultMSGraphSchemaLoader()
+	resources := msgraphTypes.ListResources("v1.0")
+	if len(resources) > 20 {
+		resources = resources[:20]
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		for _, res := range resources {
+			wg.Add(1)
+			go func(url string) {
+				defer wg.Done()
+				def := msgraphTypes.GetResourceDefinition("v1.0", url)
+				if def == nil {
+					t.Errorf("failed to load resource definition for %s api-version %s", url, "v1.0")
+					return
+				}
+				def.Validate(map[string]interface{}{}, "")
+			}(res.Url)
+		}
+	}
+	wg.Wait()
+}
+
+func Test_CacheStats(t *testing.T) {
+	msgraphTypes := DefaultMSGraphSchemaLoader()
+	msgraphTypes.GetResourceDefinition("v1.0", "/applications")
+	msgraphTypes.GetResourceDefinition("v1.0", "/applications")
+
+	stats := msgraphTypes.CacheStats()
+	if len(stats) != 1 || stats[0].APIVersion != "v1.0" {
+		t.Fatalf("expect the stats of api version v1.0 but got %v", stats)
+	}
+	if stats[0].Entries == 0 || stats[0].Hits == 0 || stats[0].Misses == 0 {
+		t.Errorf("expect entries, hits and misses to be counted but got %+v", stats[0])
+	}
+
+	msgraphTypes.EvictCache("v1.0")
+	stats = msgraphTypes.CacheStats()
+	if stats[0].Entries != 0 {
+		t.Errorf("expect the cache to be evicted but got %+v", stats[0])
+	}
+
+	msgraphTypes.GetResourceDefinition("v1.0", "/applications")
+	msgraphTypes.ResetCache()
+	stats = msgraphTypes.CacheStats()
+	if stats[0].Entries != 0 {
+		t.Errorf("expect the cache to be reset but got %+v", stats[0])
+	}
+}
+
+func Test_MemoryBoundedLoader(t *testing.T) {
+	msgraphTypes := DefaultMSGraphSchemaLoader(WithDocumentsDropped(), WithMaxDefinitions(2))
+	for _, url := range []string{"/applications", "/groups", "/users"} {
+		if msgraphTypes.GetResourceDefinition("v1.0", url) == nil {
+			t.Fatalf("failed to load resource definition for %s api-version %s", url, "v1.0")
+		}
+	}
+
+	usages := msgraphTypes.MemoryUsage()
+	if len(usages) != 1 || usages[0].APIVersion != "v1.0" {
+		t.Fatalf("expect the memory usage of api version v1.0 but got %v", usages)
+	}
+	if usages[0].DocumentRetained {
+		t.Errorf("expect the document to be dropped")
+	}
+	if usages[0].Paths == 0 || usages[0].Schemas == 0 || usages[0].SchemaBytes == 0 || usages[0].ConvertedTypes == 0 {
+		t.Errorf("expect the paths, schemas, schema bytes and converted types to be reported but got %+v", usages[0])
+	}
+	if usages[0].Definitions != 2 {
+		t.Errorf("expect %d definitions but got %d", 2, usages[0].Definitions)
+	}
+	if stats := msgraphTypes.CacheStats(); len(stats) != 1 || stats[0].Entries != usages[0].ConvertedTypes || stats[0].Hits == 0 {
+		t.Errorf("expect the definitions to share the conversion cache but got %v", stats)
+	}
+
+	if msgraphTypes.GetSchema("v1.0") == nil {
+		t.Errorf("failed to load azure schema version %s", "v1.0")
+	}
+
+	msgraphTypes.EvictSchema("v1.0")
+	if usages := msgraphTypes.MemoryUsage(); len(usages) != 0 {
+		t.Errorf("expect the schema to be evicted but got %v", usages)
+	}
+	if msgraphTypes.GetResourceDefinition("v1.0", "/applications") == nil {
+		t.Errorf("failed to load resource definition for %s api-version %s", "/applications", "v1.0")
+	}
+}
+
+func Test_MaxConvertedTypes(t *testing.T) {
+	msgraphTypes := NewMSGraphSchemaLoader(os.DirFS("testdata"), WithMaxConvertedTypes(2))
+
+	released := make(chan struct{})
+	func() {
+		widget := msgraphTypes.GetTypeDefinition("v1.0", "microsoft.graph.widget")
+		if widget == nil {
+			t.Fatalf("failed to load type definition %s", "microsoft.graph.widget")
+		}
+		runtime.SetFinalizer((*widget).(*ObjectType), func(*ObjectType) { close(released) })
+	}()
+	if stats := msgraphTypes.CacheStats(); len(stats) != 1 || stats[0].Entries > 2 || stats[0].Evictions == 0 {
+		t.Fatalf("expect at most %d converted types to be kept but got %v", 2, stats)
+	}
+
+	// the widget isn't referenced by the gadget, so it's evicted by the conversion of the gadget
+	if msgraphTypes.GetTypeDefinition("v1.0", "microsoft.graph.gadget") == nil {
+		t.Fatalf("failed to load type definition %s", "microsoft.graph.gadget")
+	}
+	deadline := time.After(5 * time.Second)
+	for waiting := true; waiting; {
+		runtime.GC()
+		select {
+		case <-released:
+			waiting = false
+		case <-deadline:
+			t.Fatalf("expect the evicted type to be released")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	misses := msgraphTypes.CacheStats()[0].Misses
+	if msgraphTypes.GetTypeDefinition("v1.0", "microsoft.graph.widget") == nil {
+		t.Fatalf("failed to load type definition %s", "microsoft.graph.widget")
+	}
+	if actual := msgraphTypes.CacheStats()[0].Misses; actual != misses+1 {
+		t.Errorf("expect the evicted type to be converted again but got %d misses", actual-misses)
+	}
+}
+
+func Test_QueryResources(t *testing.T) {
+	msgraphTypes := DefaultMSGraphSchemaLoader()
+	testcases := []struct {
+		Query ResourceQuery
+		Urls  []string
+	}{
+		{
+			Query: ResourceQuery{UrlPrefix: "/applications/{id}"},
+			Urls:  []string{"/applications/{application-id}/owners/$ref", "/applications/{application-id}/extensionProperties"},
+		},
+		{
+			Query: ResourceQuery{EntityType: "group"},
+			Urls:  []string{"/groups"},
+		},
+		{
+			Query: ResourceQuery{Tag: "users.user"},
+			Urls:  []string{"/users"},
+		},
+		{
+			Query: ResourceQuery{Text: "DIRECTORY EXTENSION"},
+			Urls:  []string{"/applications/{application-id}/extensionProperties"},
+		},
+		{
+			Query: ResourceQuery{Kinds: []ResourceKind{ResourceKindReference}},
+			Urls:  []string{"/applications/{application-id}/owners/$ref"},
+		},
+		{
+			Query: ResourceQuery{Kinds: []ResourceKind{ResourceKindReadable}, EntityType: "microsoft.graph.application", UrlPrefix: "/applications("},
+			Urls:  []string{"/applications(appId='{appId}')"},
+		},
+	}
+
+	for _, testcase := range testcases {
+		resources := msgraphTypes.QueryResources("v1.0", testcase.Query)
+		urls := make([]string, 0)
+		for _, resource := range resources {
+			urls = append(urls, resource.Url)
+		}
+		if !reflect.DeepEqual(urls, testcase.Urls) {
+			t.Errorf("expect %v but got %v for query %+v", testcase.Urls, urls, testcase.Query)
+		}
+	}
+
+	resource := msgraphTypes.FindResource("v1.0", "/applications/{id}")
+	if resource == nil || resource.Url != "/applications/{application-id}" || resource.Kind != ResourceKindReadable {
+		t.Errorf("expect the readable resource /applications/{application-id} but got %+v", resource)
+	}
+}
+
+func Test_TypeDefinitions(t *testing.T) {
+	msgraphTypes := DefaultMSGraphSchemaLoader()
+
+	kinds := make(map[string]TypeKind)
+	for _, definition := range msgraphTypes.ListTypeDefinitions("v1.0") {
+		kinds[definition.Name] = definition.Kind
+	}
+	expectedKinds := map[string]TypeKind{
+		"microsoft.graph.entity":             TypeKindEntity,
+		"microsoft.graph.application":        TypeKindEntity,
+		"microsoft.graph.passwordCredential": TypeKindComplex,
+	}
+	for name, kind := range expectedKinds {
+		if kinds[name] != kind {
+			t.Errorf("expect %s to be %s but got %q", name, kind, kinds[name])
+		}
+	}
+	for _, name := range []string{"microsoft.graph.applicationCollectionResponse", "ReferenceCreate"} {
+		if _, ok := kinds[name]; ok {
+			t.Errorf("expect %s not to be listed", name)
+		}
+	}
+
+	definition := msgraphTypes.GetTypeDefinition("v1.0", "microsoft.graph.application")
+	if definition == nil {
+		t.Fatalf("failed to load type definition %s", "microsoft.graph.application")
+	}
+	if objectType, ok := (*definition).(*ObjectType); !ok || objectType.Properties["appId"].Type == nil {
+		t.Errorf("expect an object type with property appId but got %v", *definition)
+	}
+	if msgraphTypes.GetTypeDefinition("v1.0", "microsoft.graph.notExist") != nil {
+		t.Errorf("expect nil for the type which doesn't exist")
+	}
+
+	urls := make([]string, 0)
+	for _, resource := range msgraphTypes.ListEntityTypeResources("v1.0")["microsoft.graph.extensionProperty"] {
+		urls = append(urls, resource.Url)
+	}
+	if expected := []string{"/applications/{application-id}/extensionProperties"}; !reflect.DeepEqual(urls, expected) {
+		t.Errorf("expect %v but got %v", expected, urls)
+	}
+}
+
+func Test_TypeDefinitionsCached(t *testing.T) {
+	msgraphTypes := NewMSGraphSchemaLoader(os.DirFS("testdata"), WithDocumentsDropped(), WithMaxDefinitions(2))
+	first := msgraphTypes.GetTypeDefinition("v1.0", "microsoft.graph.widget")
+	if first == nil {
+		t.Fatalf("failed to load type definition %s", "microsoft.graph.widget")
+	}
+	misses := msgraphTypes.CacheStats()[0].Misses
+	if second := msgraphTypes.GetTypeDefinition("v1.0", "microsoft.graph.widget"); second != first {
+		t.Errorf("expect the cached type definition to be returned")
+	}
+	if actual := msgraphTypes.CacheStats()[0].Misses; actual != misses {
+		t.Errorf("expect the type definition not to be converted again but got %d misses", actual-misses)
+	}
+
+	usages := msgraphTypes.MemoryUsage()
+	if len(usages) != 1 || usages[0].Definitions != 1 || usages[0].ConvertedTypes == 0 {
+		t.Fatalf("expect the type definition to be counted but got %v", usages)
+	}
+	if expected := len(msgraphTypes.ListTypeDefinitions("v1.0")); usages[0].Schemas != expected {
+		t.Errorf("expect only the %d schemas of the type definitions to be kept but got %d", expected, usages[0].Schemas)
+	}
+
+	msgraphTypes.GetResourceDefinition("v1.0", "/widgets")
+	msgraphTypes.GetResourceDefinition("v1.0", "/gadgets")
+	if usages := msgraphTypes.MemoryUsage(); usages[0].Definitions != 2 {
+		t.Errorf("expect %d definitions but got %d", 2, usages[0].Definitions)
+	}
+}
+
+func Test_TypeHierarchy(t *testing.T) {
+	msgraphTypes := DefaultMSGraphSchemaLoader()
+
+	derived := make(map[string]TypeDefinition)
+	for _, definition := range msgraphTypes.ListDerivedTypes("v1.0", "microsoft.graph.directoryObject") {
+		derived[definition.Name] = definition
+	}
+	for _, name := range []string{"microsoft.graph.user", "microsoft.graph.group", "microsoft.graph.application"} {
+		if definition, ok := derived[name]; !ok || definition.BaseType != "microsoft.graph.directoryObject" {
+			t.Errorf("expect %s to be derived from microsoft.graph.directoryObject but got %v", name, definition)
+		}
+	}
+	for _, name := range []string{"microsoft.graph.directoryObject", "microsoft.graph.entity", "microsoft.graph.passwordCredential"} {
+		if _, ok := derived[name]; ok {
+			t.Errorf("expect %s not to be derived from microsoft.graph.directoryObject", name)
+		}
+	}
+	if len(msgraphTypes.ListDerivedTypes("v1.0", "microsoft.graph.entity")) <= len(derived) {
+		t.Errorf("expect the types derived from microsoft.graph.entity to include the ones of microsoft.graph.directoryObject")
+	}
+
+	definition := msgraphTypes.GetTypeDefinition("v1.0", "microsoft.graph.user")
+	if definition == nil {
+		t.Fatalf("failed to load type definition %s", "microsoft.graph.user")
+	}
+	objectType, ok := (*definition).(*ObjectType)
+	if !ok {
+		t.Fatalf("expect an object type but got %T", *definition)
+	}
+	if expected := []string{"microsoft.graph.directoryObject", "microsoft.graph.entity"}; !reflect.DeepEqual(objectType.BaseTypes, expected) {
+		t.Errorf("expect base types %v but got %v", expected, objectType.BaseTypes)
+	}
+	if objectType.BaseType == nil {
+		t.Fatalf("expect the base type of microsoft.graph.user")
+	}
+	baseType, ok := objectType.BaseType.Type.(*ObjectType)
+	if !ok || baseType.Name != "directoryObject" || baseType.BaseType == nil {
+		t.Errorf("expect the base type to be directoryObject but got %v", objectType.BaseType.Type)
+	}
+}
+
+func Test_Permissions(t *testing.T) {
+	msgraphTypes := NewMSGraphSchemaLoader(os.DirFS("testdata"))
+
+	resource := msgraphTypes.GetResourceDefinition("v1.0", "/widgets")
+	if resource == nil {
+		t.Fatalf("failed to load resource definition for %s api-version %s", "/widgets", "v1.0")
+	}
+	var create *OperationPermissions
+	methods := make([]string, 0)
+	for i, operation := range resource.Permissions {
+		methods = append(methods, operation.Method+" "+operation.Url)
+		if operation.Method == "POST" && operation.Url == "/widgets" {
+			create = &resource.Permissions[i]
+		}
+	}
+	expectedMethods := []string{"GET /widgets", "POST /widgets", "DELETE /widgets/{widget-id}", "GET /widgets/{widget-id}", "PATCH /widgets/{widget-id}"}
+	if !reflect.DeepEqual(methods, expectedMethods) {
+		t.Fatalf("expect %v but got %v", expectedMethods, methods)
+	}
+	if expected := []string{"Widget.ReadWrite.OwnedBy"}; !reflect.DeepEqual(create.LeastPrivileged[PermissionTypeApplication], expected) {
+		t.Errorf("expect %v but got %v", expected, create.LeastPrivileged[PermissionTypeApplication])
+	}
+	if expected := []string{"Widget.ReadWrite.All", "Widget.ReadWrite.OwnedBy"}; !reflect.DeepEqual(create.All[PermissionTypeApplication], expected) {
+		t.Errorf("expect %v but got %v", expected, create.All[PermissionTypeApplication])
+	}
+
+	resources, err := msgraphTypes.ListResourcesByPermissions("v1.0", PermissionSet{PermissionTypeApplication: {"Widget.ReadWrite.OwnedBy"}, PermissionTypeDelegatedWork: {"Gadget.Read"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	urls := make([]string, 0)
+	for _, resource := range resources {
+		urls = append(urls, string(resource.Kind)+" "+resource.Url)
+	}
+	expectedUrls := []string{"collection /widgets", "readable /gadgets/{gadget-id}"}
+	if !reflect.DeepEqual(urls, expectedUrls) {
+		t.Errorf("expect %v but got %v", expectedUrls, urls)
+	}
+}
+
+func Test_PermissionSet(t *testing.T) {
+	data, err := os.ReadFile("testdata/openapi/v1.0/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	permissions := `{
+  "permissions": {
+    "Widget.ReadWrite.All": {
+      "pathSets": [
+        {
+          "schemeKeys": ["Application"],
+          "methods": ["GET", "POST"],
+          "paths": {"/widgets": "least=Application"}
+        }
+      ]
+    },
+    "Gadget.Read": {
+      "pathSets": [
+        {
+          "schemeKeys": ["DelegatedWork"],
+          "methods": ["GET"],
+          "paths": {"/gadgets/{id}": "least=DelegatedWork"}
+        }
+      ]
+    }
+  }
+}`
+
+	cases := []struct {
+		permissionsFile string
+		permissions     PermissionSet
+		expectUrls      []string
+		expectError     bool
+	}{
+		{
+			permissionsFile: permissions,
+			permissions:     PermissionSet{PermissionTypeApplication: {"widget.readwrite.all"}},
+			expectUrls:      []string{"collection /widgets", "readable /widgets"},
+		},
+		{
+			permissionsFile: permissions,
+			permissions:     PermissionSet{PermissionTypeApplication: {"Widget.ReadWrite.All"}, PermissionTypeDelegatedWork: {"Gadget.Read"}},
+			expectUrls:      []string{"collection /widgets", "readable /gadgets/{gadget-id}", "readable /widgets"},
+		},
+		{
+			permissionsFile: permissions,
+			permissions:     PermissionSet{PermissionTypeApplication: {"Gadget.Read"}},
+			expectUrls:      []string{},
+		},
+		{
+			permissionsFile: "{",
+			permissions:     PermissionSet{PermissionTypeApplication: {"Widget.ReadWrite.All"}},
+			expectError:     true,
+		},
+		{
+			// the permissions file doesn't exist
+			permissionsFile: "",
+			permissions:     PermissionSet{PermissionTypeApplication: {"Widget.ReadWrite.All"}},
+			expectError:     true,
+		},
+	}
+
+	for _, c := range cases {
+		files := fstest.MapFS{
+			"openapi/v1.0/openapi.yaml": &fstest.MapFile{Data: data},
+		}
+		if c.permissionsFile != "" {
+			files["openapi/permissions.json"] = &fstest.MapFile{Data: []byte(c.permissionsFile)}
+		}
+		msgraphTypes := NewMSGraphSchemaLoader(files)
+		if msgraphTypes.GetResourceDefinition("v1.0", "/widgets") == nil {
+			t.Errorf("failed to load resource definition for %s api-version %s", "/widgets", "v1.0")
+			continue
+		}
+		resources, err := msgraphTypes.ListResourcesByPermissions("v1.0", c.permissions)
+		if c.expectError != (err != nil) {
+			t.Errorf("expect error %v but got %v", c.expectError, err)
+			continue
+		}
+		if c.expectError {
+			continue
+		}
+		urls := make([]string, 0)
+		for _, resource := range resources {
+			urls = append(urls, string(resource.Kind)+" "+resource.Url)
+		}
+		if !reflect.DeepEqual(urls, c.expectUrls) {
+			t.Errorf("expect %v but got %v", c.expectUrls, urls)
+		}
+	}
+}
+
+func Test_Sample(t *testing.T) {
+	// the random samples of all resources take minutes, only the deterministic samples are checked in short mode
+	seeds := int64(20)
+	if testing.Short() {
+		seeds = 0
+	}
+	msgraphTypes := DefaultMSGraphSchemaLoader()
+	for _, version := range availableAPIVersions() {
+		for _, resource := range msgraphTypes.ListResources(version) {
+			definition := msgraphTypes.GetResourceDefinition(version, resource.Url)
+			if definition == nil {
+				continue
+			}
+			for _, mode := range []SampleMode{SampleMinimal, SampleFull} {
+				options := []*SampleOptions{{Mode: mode}}
+				for seed := int64(0); seed < seeds; seed++ {
+					options = append(options, &SampleOptions{Mode: mode, Rand: rand.New(rand.NewSource(seed))})
+				}
+				for _, option := range options {
+					body := definition.Sample(option)
+					if errs := definition.Validate(body, ""); len(errs) != 0 {
+						t.Errorf("expect the sample of %s api-version %s to be valid but got %v: %v", resource.Url, version, errs, body)
+					}
+				}
+			}
+		}
+	}
+
+	definition := msgraphTypes.GetResourceDefinition("v1.0", "/applications")
+	minimal, _ := definition.Sample(nil).(map[string]interface{})
+	full, _ := definition.Sample(&SampleOptions{Mode: SampleFull}).(map[string]interface{})
+	if len(minimal) >= len(full) || full["displayName"] == nil {
+		t.Errorf("expect the full sample to have more properties than the minimal sample but got %v and %v", minimal, full)
+	}
+	if _, ok := full["deletedDateTime"]; ok {
+		t.Errorf("expect the read-only property deletedDateTime not to be generated")
+	}
+}
+
+func Test_SampleAnnotations(t *testing.T) {
+	msgraphTypes := NewMSGraphSchemaLoader(os.DirFS("testdata"))
+	cases := []struct {
+		url           string
+		options       *SampleOptions
+		expectKeys    []string
+		notExpectKeys []string
+	}{
+		{
+			url:           "/widgets",
+			options:       nil,
+			expectKeys:    []string{"@odata.type", "displayName"},
+			notExpectKeys: []string{"owners@odata.bind", "legacyName"},
+		},
+		{
+			url:           "/widgets",
+			options:       &SampleOptions{Mode: SampleFull},
+			expectKeys:    []string{"@odata.type", "displayName", "owners@odata.bind"},
+			notExpectKeys: []string{"owners", "parts", "parts@odata.bind", "legacyName", "createdDateTime"},
+		},
+		{
+			url:        "/widgets/{widget-id}/owners/$ref",
+			options:    nil,
+			expectKeys: []string{"@odata.id"},
+		},
+		{
+			url:        "/widgets/{widget-id}/owners/$ref",
+			options:    &SampleOptions{Mode: SampleFull, Rand: rand.New(rand.NewSource(1))},
+			expectKeys: []string{"@odata.id"},
+		},
+	}
+
+	for _, c := range cases {
+		definition := msgraphTypes.GetResourceDefinition("v1.0", c.url)
+		if definition == nil {
+			t.Fatalf("failed to load resource definition for %s api-version %s", c.url, "v1.0")
+		}
+		body, _ := definition.Sample(c.options).(map[string]interface{})
+		for _, key := range c.expectKeys {
+			if _, ok := body[key]; !ok {
+				t.Errorf("expect the sample of %s to have %s but got %v", c.url, key, body)
+			}
+		}
+		for _, key := range c.notExpectKeys {
+			if _, ok := body[key]; ok {
+				t.Errorf("expect the sample of %s not to have %s but got %v", c.url, key, body)
+			}
+		}
+		if errs := definition.Validate(body, ""); len(errs) != 0 {
+			t.Errorf("expect the sample of %s to be valid but got %v: %v", c.url, errs, body)
+		}
+	}
+}
+
+func Test_SamplePattern(t *testing.T) {
+	patterns := []string{
+		`^[0-9]{4,}-(0[1-9]|1[012])-(0[1-9]|[12][0-9]|3[01])T([01][0-9]|2[0-3]):([0-5][0-9]):([0-5][0-9])([.][0-9]{1,12})?(Z|[+-][0-9][0-9]:[0-9][0-9])$`,
+		`^[a-zA-Z0-9_]+@[a-z]+\.com$`,
+		`^(?i)sp-[a-f0-9]{8}$`,
+		`^[^a]{8}$`,
+		`^[\x{80}-\x{10FFFF}]$`,
+	}
+	for _, pattern := range patterns {
+		for seed := int64(-1); seed < 20; seed++ {
+			options := &SampleOptions{Rand: rand.New(rand.NewSource(seed))}
+			if seed < 0 {
+				options = &SampleOptions{}
+			}
+			value, ok := samplePattern(pattern, options)
+			if !ok {
+				t.Errorf("expect the sample %q to match the pattern %s", value, pattern)
+			}
+			for _, r := range value {
+				if !unicode.IsPrint(r) {
+					t.Errorf("expect the sample %q of the pattern %s to be printable", value, pattern)
+					break
+				}
+			}
+		}
+	}
+}
+
+func Test_SampleRecursiveType(t *testing.T) {
+	node := &ObjectType{Type: "object", Properties: map[string]ObjectProperty{
+		"name": {Type: &TypeReference{Type: &StringType{Type: "string"}}, Flags: []ObjectPropertyFlag{Required}},
+	}}
+	node.Properties["parent"] = ObjectProperty{Type: &TypeReference{Type: node}, Flags: []ObjectPropertyFlag{Required}}
+
+	for _, options := range []*SampleOptions{nil, {Mode: SampleFull}, {Mode: SampleFull, MaxDepth: 2}} {
+		depth := 0
+		for value, ok := Sample(node, options).(map[string]interface{}); ok && len(value) != 0; value, ok = value["parent"].(map[string]interface{}) {
+			depth++
+		}
+		maxDepth := 8
+		if options != nil && options.MaxDepth != 0 {
+			maxDepth = options.MaxDepth
+		}
+		if depth != maxDepth+1 {
+			t.Errorf("expect %d nested objects but got %d", maxDepth+1, depth)
+		}
+	}
+}
+
 func Test_AllMSGraphTypes(t *testing.T) {
 	msgraphTypes := DefaultMSGraphSchemaLoader()
 	for _, apiVersion := range msgraphTypes.ListAPIVersions() {
@@ -94,3 +786,329 @@ func Test_AllMSGraphTypes(t *testing.T) {
 		}
 	}
 }
+
+func Test_ValidateODataBind(t *testing.T) {
+	msgraphTypes := DefaultMSGraphSchemaLoader()
+	def := msgraphTypes.GetResourceDefinition("v1.0", "/applications")
+	if def == nil {
+		t.Fatalf("failed to load resource definition for %s api-version %s", "/applications", "v1.0")
+	}
+
+	cases := []struct {
+		url         string
+		expectError bool
+	}{
+		{"https://graph.microsoft.com/v1.0/directoryObjects/00000000-0000-0000-0000-000000000000", false},
+		{"https://graph.microsoft.com/v1.0/users/00000000-0000-0000-0000-000000000000", false},
+		{"https://graph.microsoft.com/v1.0/applications(appId='00000000-0000-0000-0000-000000000000')", false},
+		{"https://graph.microsoft.com/v1.0/unknown/00000000-0000-0000-0000-000000000000", true},
+		{"https://graph.microsoft.com/beta/users/00000000-0000-0000-0000-000000000000", true},
+	}
+
+	for _, c := range cases {
+		body := map[string]interface{}{
+			"displayName":       "foo",
+			"owners@odata.bind": []interface{}{c.url},
+		}
+		errors := def.Validate(body, "")
+		if c.expectError != (len(errors) != 0) {
+			t.Errorf("expect error %v but got %v for url %s", c.expectError, errors, c.url)
+		}
+		// the body type has no resolver, only the shape of the annotation is checked
+		if errors := def.Body.Type.Validate(body, ""); len(errors) != 0 {
+			t.Errorf("expect no error of the body type but got %v for url %s", errors, c.url)
+		}
+	}
+}
+
+func Test_ODataBindAssignable(t *testing.T) {
+	odataType := func(name string) ObjectProperty {
+		value := "#" + name
+		return ObjectProperty{Type: &TypeReference{Type: &StringType{Type: "string", Default: &value}}}
+	}
+	directoryObject := &ObjectType{
+		Type:       "object",
+		Name:       "directoryObject",
+		BaseTypes:  []string{"microsoft.graph.entity"},
+		Properties: map[string]ObjectProperty{"@odata.type": odataType("microsoft.graph.directoryObject")},
+	}
+	copiedDirectoryObject := *directoryObject
+	entity := &ObjectType{
+		Type:       "object",
+		Name:       "entity",
+		Properties: map[string]ObjectProperty{"id": {Type: &TypeReference{Type: &StringType{Type: "string"}}}},
+	}
+	user := &ObjectType{
+		Type:       "object",
+		Name:       "user",
+		BaseTypes:  []string{"microsoft.graph.directoryObject", "microsoft.graph.entity"},
+		Properties: map[string]ObjectProperty{"@odata.type": odataType("microsoft.graph.user")},
+	}
+	drive := &ObjectType{
+		Type:      "object",
+		Name:      "drive",
+		BaseTypes: []string{"microsoft.graph.baseItem", "microsoft.graph.entity"},
+		Properties: map[string]ObjectProperty{
+			"@odata.type":     odataType("microsoft.graph.drive"),
+			"deletedDateTime": {Type: &TypeReference{Type: &StringType{Type: "string"}}},
+		},
+	}
+
+	cases := []struct {
+		t        TypeBase
+		target   TypeBase
+		expected bool
+	}{
+		{directoryObject, directoryObject, true},
+		{&copiedDirectoryObject, directoryObject, true},
+		{user, directoryObject, true},
+		{user, entity, true},
+		{drive, directoryObject, false},
+		{directoryObject, user, false},
+		{&StringType{Type: "string"}, directoryObject, false},
+		{drive, nil, true},
+	}
+
+	for _, c := range cases {
+		if actual := isAssignableTo(c.t, c.target); actual != c.expected {
+			t.Errorf("expect %v but got %v for %s and %s", c.expected, actual, typeSummary(c.t), typeSummary(c.target))
+		}
+	}
+}
+
+func Test_Redact(t *testing.T) {
+	cases := []struct {
+		url      string
+		options  []LoaderOption
+		body     map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			url: "/applications",
+			body: map[string]interface{}{
+				"displayName": "foo",
+				"passwordCredentials": []interface{}{
+					map[string]interface{}{
+						"displayName": "bar",
+						"secretText":  "password",
+					},
+				},
+			},
+			expected: map[string]interface{}{
+				"displayName": "foo",
+				"passwordCredentials": []interface{}{
+					map[string]interface{}{
+						"displayName": "bar",
+						"secretText":  RedactedValue,
+					},
+				},
+			},
+		},
+		{
+			url: "/users",
+			body: map[string]interface{}{
+				"displayName": "foo",
+				"passwordProfile": map[string]interface{}{
+					"forceChangePasswordNextSignIn": true,
+					"password":                      "password",
+				},
+			},
+			expected: map[string]interface{}{
+				"displayName": "foo",
+				"passwordProfile": map[string]interface{}{
+					"forceChangePasswordNextSignIn": true,
+					"password":                      RedactedValue,
+				},
+			},
+		},
+		{
+			url:     "/applications",
+			options: []LoaderOption{WithSensitivePropertyRules(SensitivePropertyRule{Pattern: regexp.MustCompile(`^displayName$`), Sensitive: true})},
+			body: map[string]interface{}{
+				"displayName": "foo",
+				"passwordCredentials": []interface{}{
+					map[string]interface{}{
+						"displayName": "bar",
+						"secretText":  "password",
+					},
+				},
+			},
+			expected: map[string]interface{}{
+				"displayName": RedactedValue,
+				"passwordCredentials": []interface{}{
+					map[string]interface{}{
+						"displayName": RedactedValue,
+						"secretText":  "password",
+					},
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		def := DefaultMSGraphSchemaLoader(c.options...).GetResourceDefinition("v1.0", c.url)
+		if def == nil {
+			t.Fatalf("failed to load resource definition for %s api-version %s", c.url, "v1.0")
+		}
+		actual := def.Redact(c.body)
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expect %v but got %v", c.expected, actual)
+		}
+	}
+}
+
+func Test_RedactUnion(t *testing.T) {
+	unionType := &UnionType{
+		Type: "union",
+		Elements: []*TypeReference{
+			{Type: &StringType{Type: "string", Sensitive: true}},
+			{Type: &ObjectType{
+				Type: "object",
+				Properties: map[string]ObjectProperty{
+					"hint": {Type: &TypeReference{Type: &StringType{Type: "string"}}},
+				},
+			}},
+		},
+	}
+
+	cases := []struct {
+		body     interface{}
+		expected interface{}
+	}{
+		{
+			body:     "secret",
+			expected: RedactedValue,
+		},
+		{
+			body:     map[string]interface{}{"hint": "foo"},
+			expected: map[string]interface{}{"hint": "foo"},
+		},
+		{
+			body:     []interface{}{"secret"},
+			expected: []interface{}{"secret"},
+		},
+	}
+
+	for _, c := range cases {
+		if actual := unionType.Redact(c.body); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expect %v but got %v", c.expected, actual)
+		}
+	}
+}
+
+func Test_RedactSensitiveObject(t *testing.T) {
+	objectType := &ObjectType{
+		Type:      "object",
+		Sensitive: true,
+		Properties: map[string]ObjectProperty{
+			"key":     {Type: &TypeReference{Type: &StringType{Type: "string"}}},
+			"enabled": {Type: &TypeReference{Type: &BooleanType{Type: "boolean"}}},
+		},
+	}
+	body := map[string]interface{}{
+		"@odata.type": "#microsoft.graph.secret",
+		"key":         "secret",
+		"enabled":     true,
+		"values":      []interface{}{"secret", float64(1)},
+	}
+	expected := map[string]interface{}{
+		"@odata.type": "#microsoft.graph.secret",
+		"key":         RedactedValue,
+		"enabled":     true,
+		"values":      []interface{}{RedactedValue, float64(1)},
+	}
+	if actual := objectType.Redact(body); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expect %v but got %v", expected, actual)
+	}
+}
+
+func Test_SensitiveRecursiveObject(t *testing.T) {
+	node := &openapi3.Schema{
+		Type:       &openapi3.Types{"object"},
+		Title:      "node",
+		Properties: openapi3.Schemas{"name": {Value: openapi3.NewStringSchema()}},
+	}
+	node.Properties["passwordNode"] = &openapi3.SchemaRef{Value: node}
+
+	converted := NewTypeBaseFromOpenAPISchema(node, map[*openapi3.Schema]*TypeBase{})
+	if converted == nil {
+		t.Fatalf("failed to convert the schema")
+	}
+	property := (*converted).(*ObjectType).Properties["passwordNode"]
+	objectType, ok := property.Type.Type.(*ObjectType)
+	if !ok || !objectType.Sensitive {
+		t.Fatalf("expect the recursive property to be a sensitive object but got %v", property.Type.Type)
+	}
+	if len(objectType.Properties) != 2 {
+		t.Errorf("expect the sensitive copy to have the properties of the object but got %v", objectType.Properties)
+	}
+}
+
+func Test_ResourcePropertyFlags(t *testing.T) {
+	msgraphTypes := DefaultMSGraphSchemaLoader()
+	def := msgraphTypes.GetResourceDefinition("v1.0", "/applications")
+	if def == nil || def.Body == nil {
+		t.Fatalf("failed to load resource definition for %s api-version %s", "/applications", "v1.0")
+	}
+	objectType, ok := def.Body.Type.(*ObjectType)
+	if !ok {
+		t.Fatalf("expect object type but got %T", def.Body.Type)
+	}
+	for _, key := range []string{"id", "appId"} {
+		property := objectType.Properties[key]
+		if !property.IsIdentifier() {
+			t.Errorf("expect %s to be an identifier", key)
+		}
+	}
+
+	// the alternate keys are writable, so they're configurable
+	filtered := def.FilterConfigurableFields(map[string]interface{}{
+		"appId":       "00000000-0000-0000-0000-000000000000",
+		"displayName": "foo",
+	})
+	if _, ok := filtered.(map[string]interface{})["appId"]; !ok {
+		t.Errorf("expect appId to be kept but got %v", filtered)
+	}
+
+	loader := NewMSGraphSchemaLoader(os.DirFS("testdata"))
+	cases := []struct {
+		url              string
+		expectCreateOnly []string
+	}{
+		{
+			// the PATCH body doesn't accept these properties, the navigation properties and the alternate key `code` aren't flagged
+			url:              "/widgets",
+			expectCreateOnly: []string{"labels", "legacyName", "password", "region", "secretKey", "value"},
+		},
+		{
+			// there's no PATCH operation
+			url:              "/widgets/{widget-id}/parts",
+			expectCreateOnly: []string{},
+		},
+		{
+			// the PATCH operation accepts the same schema
+			url:              "/gadgets",
+			expectCreateOnly: []string{},
+		},
+	}
+	for _, c := range cases {
+		def := loader.GetResourceDefinition("v1.0", c.url)
+		if def == nil || def.Body == nil {
+			t.Fatalf("failed to load resource definition for %s api-version %s", c.url, "v1.0")
+		}
+		objectType, ok := def.Body.Type.(*ObjectType)
+		if !ok {
+			t.Fatalf("expect object type but got %T", def.Body.Type)
+		}
+		actual := make([]string, 0)
+		for key, property := range objectType.Properties {
+			if property.IsDeployTimeConstant() {
+				actual = append(actual, key)
+			}
+		}
+		sort.Strings(actual)
+		if !reflect.DeepEqual(actual, c.expectCreateOnly) {
+			t.Errorf("expect create-only properties %v but got %v for %s", c.expectCreateOnly, actual, c.url)
+		}
+	}
+}
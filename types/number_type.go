@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"math"
 )
 
 var _ TypeBase = &NumberType{}
@@ -11,10 +12,15 @@ type NumberType struct {
 	Format   string   `json:"format"`
 	MinValue *float64 `json:"minValue"`
 	MaxValue *float64 `json:"maxValue"`
+	Default  *float64 `json:"default"`
 }
 
 func (t *NumberType) Validate(body interface{}, path string) []error {
-	if body == nil {
+	return t.Diagnose(body, path, nil).Errors()
+}
+
+func (t *NumberType) Diagnose(body interface{}, path string, options *ValidationOptions) Diagnostics {
+	if body == nil || IsUnknown(body) {
 		return nil
 	}
 	var v int
@@ -30,13 +36,13 @@ func (t *NumberType) Validate(body interface{}, path string) []error {
 	case int:
 		v = input
 	default:
-		return []error{ErrorMismatch(path, "integer", fmt.Sprintf("%T", body))}
+		return errorDiagnostics(path, ErrorMismatch(path, "integer", fmt.Sprintf("%T", body)))
 	}
 	if t.MinValue != nil && float64(v) < *t.MinValue {
-		return []error{ErrorCommon(path, fmt.Sprintf("value is less than %v", *t.MinValue))}
+		return errorDiagnostics(path, ErrorCommon(path, fmt.Sprintf("value is less than %v", *t.MinValue)))
 	}
 	if t.MaxValue != nil && float64(v) > *t.MaxValue {
-		return []error{ErrorCommon(path, fmt.Sprintf("value is greater than %v", *t.MaxValue))}
+		return errorDiagnostics(path, ErrorCommon(path, fmt.Sprintf("value is greater than %v", *t.MaxValue)))
 	}
 	return nil
 }
@@ -45,10 +51,66 @@ func (t *NumberType) FilterReadOnlyFields(i interface{}) interface{} {
 	return i
 }
 
+func (t *NumberType) ApplyDefaults(i interface{}) interface{} {
+	return i
+}
+
 func (t *NumberType) FilterConfigurableFields(i interface{}) interface{} {
 	return i
 }
 
+func (t *NumberType) FilterWriteOnlyFields(i interface{}) interface{} {
+	return i
+}
+
+func (t *NumberType) MergeWriteOnlyFields(state interface{}, config interface{}) interface{} {
+	return state
+}
+
+func (t *NumberType) Redact(i interface{}) interface{} {
+	return i
+}
+
+func (t *NumberType) Sample(options *SampleOptions) interface{} {
+	options = options.orDefault()
+	value := float64(0)
+	switch {
+	case t.Default != nil:
+		value = *t.Default
+	case options.Rand != nil:
+		min, max := float64(0), float64(100)
+		if t.MinValue != nil {
+			min = *t.MinValue
+			if t.MaxValue == nil {
+				max = min + 100
+			}
+		}
+		if t.MaxValue != nil {
+			max = *t.MaxValue
+			if t.MinValue == nil {
+				min = math.Min(0, max)
+			}
+		}
+		value = min + options.Rand.Float64()*(max-min)
+	}
+	if t.MinValue != nil && value < *t.MinValue {
+		value = *t.MinValue
+	}
+	if t.MaxValue != nil && value > *t.MaxValue {
+		value = *t.MaxValue
+	}
+
+	switch t.Format {
+	case "double", "float", "decimal":
+		return value
+	}
+	integer := int(math.Ceil(value))
+	if t.MaxValue != nil && float64(integer) > *t.MaxValue {
+		integer = int(math.Floor(value))
+	}
+	return integer
+}
+
 func (t *NumberType) AsTypeBase() *TypeBase {
 	typeBase := TypeBase(t)
 	return &typeBase
@@ -3,50 +3,75 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 )
 
 var _ TypeBase = &ObjectType{}
 
 type ObjectType struct {
-	Type                 string                    `json:"$type"`
-	Name                 string                    `json:"name"`
+	Type string `json:"$type"`
+	Name string `json:"name"`
+	// BaseTypes are the fully-qualified names of the base types, the nearest base type is the first.
+	BaseTypes []string `json:"baseTypes"`
+	// BaseType is the type of the nearest base type, its properties are also merged into this type.
+	BaseType             *TypeReference            `json:"baseType"`
 	Properties           map[string]ObjectProperty `json:"properties"`
 	AdditionalProperties *TypeReference            `json:"additionalProperties"`
 	Sensitive            bool                      `json:"sensitive"`
+	Default              map[string]interface{}    `json:"default"`
 }
 
+// Validate validates the body and returns the errors, see Diagnose.
 func (t *ObjectType) Validate(body interface{}, path string) []error {
-	if t == nil || body == nil {
-		return []error{}
+	return t.Diagnose(body, path, nil).Errors()
+}
+
+// Diagnose validates the body and reports the errors and the warnings. Only the shapes of the `@odata.bind` annotations
+// are checked, the URLs are resolved by ResourceType.Diagnose, which has a ReferenceResolver.
+func (t *ObjectType) Diagnose(body interface{}, path string, options *ValidationOptions) Diagnostics {
+	if t == nil || body == nil || IsUnknown(body) {
+		return Diagnostics{}
 	}
-	errors := make([]error, 0)
+	options = options.orDefault()
+	diagnostics := make(Diagnostics, 0)
 	// check body type
 	bodyMap, ok := body.(map[string]interface{})
 	if !ok {
-		errors = append(errors, ErrorMismatch(path, "object", fmt.Sprintf("%T", body)))
-		return errors
+		return append(diagnostics, errorDiagnostics(path, ErrorMismatch(path, "object", fmt.Sprintf("%T", body)))...)
 	}
 	// check properties defined in body, but not in schema
 	for key, value := range bodyMap {
 		if def, ok := t.Properties[key]; ok {
 			if def.IsReadOnly() {
-				errors = append(errors, ErrorShouldNotDefineReadOnly(path+"."+key))
+				diagnostics = append(diagnostics, errorDiagnostics(path+"."+key, ErrorShouldNotDefineReadOnly(path+"."+key))...)
 				continue
 			}
+			if def.Deprecated != nil {
+				diagnostics = append(diagnostics, deprecationDiagnostic(path+"."+key, def.Deprecated))
+			}
 			if def.Type != nil && def.Type.Type != nil {
-				errors = append(errors, def.Type.Type.Validate(value, path+"."+key)...)
+				diagnostics = append(diagnostics, Diagnose(def.Type.Type, value, path+"."+key, options)...)
 			}
 			continue
 		}
-		if t.AdditionalProperties != nil && t.AdditionalProperties.Type != nil {
-			errors = append(errors, t.AdditionalProperties.Type.Validate(value, path+"."+key)...)
-		} else {
-			options := make([]string, 0)
-			for key := range t.Properties {
-				options = append(options, path+"."+key)
+		if annotation, ok := ParseInstanceAnnotation(key); ok {
+			if unknownPath, suggestions, unknown := t.unknownAnnotatedProperty(annotation, path); unknown {
+				diagnostics = append(diagnostics, unknownPropertyDiagnostics(unknownPath, suggestions, options)...)
+				continue
 			}
-			errors = append(errors, ErrorShouldNotDefine(path+"."+key, options))
+			diagnostics = append(diagnostics, errorDiagnostics(path+"."+key, t.validateInstanceAnnotation(annotation, value, path)...)...)
+			continue
+		}
+		if t.AdditionalProperties != nil && t.AdditionalProperties.Type != nil {
+			diagnostics = append(diagnostics, Diagnose(t.AdditionalProperties.Type, value, path+"."+key, options)...)
+			continue
+		}
+		suggestions := make([]string, 0)
+		for key := range t.Properties {
+			suggestions = append(suggestions, path+"."+key)
 		}
+		diagnostics = append(diagnostics, unknownPropertyDiagnostics(path+"."+key, suggestions, options)...)
 	}
 
 	// check properties required in schema, but not in body
@@ -54,15 +79,130 @@ func (t *ObjectType) Validate(body interface{}, path string) []error {
 		if !value.IsRequired() {
 			continue
 		}
+		// instance annotations like `@odata.type` are inferred by the service
+		if IsInstanceAnnotation(key) {
+			continue
+		}
 		if _, ok := bodyMap[key]; !ok {
-			// skip name in body
-			if path == "" && key == "name" {
+			if options.isRequiredPropertySkipped(path + "." + key) {
 				continue
 			}
-			errors = append(errors, ErrorShouldDefine(path+"."+key))
+			diagnostics = append(diagnostics, errorDiagnostics(path+"."+key, ErrorShouldDefine(path+"."+key))...)
+		}
+	}
+	return diagnostics
+}
+
+// unknownAnnotatedProperty returns the path of the property which is annotated by the instance annotation but isn't
+// defined in the schema, and the suggested properties. The `@odata.bind` annotations must annotate the navigation
+// properties, the other annotations could also annotate the additional properties.
+func (t *ObjectType) unknownAnnotatedProperty(annotation *InstanceAnnotation, path string) (string, []string, bool) {
+	if _, ok := t.Properties[annotation.Property]; ok {
+		return "", nil, false
+	}
+	suggestions := make([]string, 0)
+	if annotation.Term == "odata.bind" {
+		for key, value := range t.Properties {
+			if value.IsNavigation() {
+				suggestions = append(suggestions, key+"@odata.bind")
+			}
 		}
+		return path + "." + annotation.Property + "@" + annotation.Term, suggestions, true
+	}
+	if annotation.Property == "" || (t.AdditionalProperties != nil && t.AdditionalProperties.Type != nil) {
+		return "", nil, false
 	}
-	return errors
+	for key := range t.Properties {
+		suggestions = append(suggestions, path+"."+key)
+	}
+	return path + "." + annotation.Property, suggestions, true
+}
+
+// unknownPropertyDiagnostics reports the property which isn't defined in the schema by the UnknownProperties policy.
+func unknownPropertyDiagnostics(path string, suggestions []string, options *ValidationOptions) Diagnostics {
+	severity := SeverityError
+	switch options.UnknownProperties {
+	case UnknownPropertyIgnore:
+		return nil
+	case UnknownPropertyWarning:
+		severity = SeverityWarning
+	}
+	return Diagnostics{{
+		Severity: severity,
+		Path:     path,
+		Err:      ErrorShouldNotDefine(path, suggestions),
+	}}
+}
+
+func (t *ObjectType) validateInstanceAnnotation(annotation *InstanceAnnotation, value interface{}, path string) []error {
+	key := annotation.Property + "@" + annotation.Term
+	if annotation.Term == "odata.bind" {
+		return t.validateODataBind(annotation.Property, value, path+"."+key)
+	}
+	return annotation.Validate(value, path+"."+key)
+}
+
+func (t *ObjectType) validateODataBind(property string, value interface{}, path string) []error {
+	def, ok := t.Properties[property]
+	if !ok {
+		return nil
+	}
+	if !def.IsNavigation() {
+		return []error{ErrorCommon(path, fmt.Sprintf("`%s` is not a navigation property", property))}
+	}
+	if def.Type == nil || def.Type.Type == nil || IsUnknown(value) {
+		return nil
+	}
+	if _, isCollection := def.Type.Type.(*ArrayType); isCollection {
+		urls, ok := value.([]interface{})
+		if !ok {
+			return []error{ErrorMismatch(path, "array", fmt.Sprintf("%T", value))}
+		}
+		errors := make([]error, 0)
+		for index, url := range urls {
+			if _, ok := url.(string); !ok && !IsUnknown(url) {
+				errors = append(errors, ErrorMismatch(fmt.Sprintf("%s.%d", path, index), "string", fmt.Sprintf("%T", url)))
+			}
+		}
+		return errors
+	}
+	if _, ok := value.(string); !ok {
+		return []error{ErrorMismatch(path, "string", fmt.Sprintf("%T", value))}
+	}
+	return nil
+}
+
+// ApplyDefaults fills the optional properties which are not specified in the body with their default values.
+func (t *ObjectType) ApplyDefaults(body interface{}) interface{} {
+	if t == nil || body == nil {
+		return body
+	}
+	bodyMap, ok := body.(map[string]interface{})
+	if !ok {
+		return body
+	}
+
+	res := make(map[string]interface{})
+	for key, value := range bodyMap {
+		if def, ok := t.Properties[key]; ok && def.Type != nil && def.Type.Type != nil {
+			res[key] = ApplyDefaults(def.Type.Type, value)
+			continue
+		}
+		if t.AdditionalProperties != nil && t.AdditionalProperties.Type != nil && !IsInstanceAnnotation(key) {
+			res[key] = ApplyDefaults(t.AdditionalProperties.Type, value)
+			continue
+		}
+		res[key] = value
+	}
+	for key, def := range t.Properties {
+		if _, ok := bodyMap[key]; ok || def.IsRequired() || def.IsReadOnly() || def.Type == nil {
+			continue
+		}
+		if value := DefaultValue(def.Type.Type); value != nil {
+			res[key] = ApplyDefaults(def.Type.Type, value)
+		}
+	}
+	return res
 }
 
 func (t *ObjectType) FilterReadOnlyFields(body interface{}) interface{} {
@@ -146,6 +286,180 @@ func (t *ObjectType) FilterConfigurableFields(body interface{}) interface{} {
 	return res
 }
 
+// FilterWriteOnlyFields removes the write-only fields, which are never returned by the service, from the body,
+// so that the body can be compared with the state read from the service.
+func (t *ObjectType) FilterWriteOnlyFields(body interface{}) interface{} {
+	if t == nil || body == nil {
+		return body
+	}
+	bodyMap, ok := body.(map[string]interface{})
+	if !ok {
+		return body
+	}
+
+	res := make(map[string]interface{})
+	for key, value := range bodyMap {
+		if def, ok := t.Properties[key]; ok {
+			if def.IsWriteOnly() {
+				continue
+			}
+			if def.Type != nil && def.Type.Type != nil {
+				res[key] = FilterWriteOnlyFields(def.Type.Type, value)
+			} else {
+				res[key] = value
+			}
+			continue
+		}
+		if t.AdditionalProperties != nil && t.AdditionalProperties.Type != nil && !IsInstanceAnnotation(key) {
+			res[key] = FilterWriteOnlyFields(t.AdditionalProperties.Type, value)
+			continue
+		}
+		res[key] = value
+	}
+	return res
+}
+
+// MergeWriteOnlyFields copies the write-only fields from the config into the state read from the service.
+func (t *ObjectType) MergeWriteOnlyFields(state interface{}, config interface{}) interface{} {
+	if t == nil || state == nil || config == nil {
+		return state
+	}
+	stateMap, ok := state.(map[string]interface{})
+	if !ok {
+		return state
+	}
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return state
+	}
+
+	res := make(map[string]interface{})
+	for key, value := range stateMap {
+		res[key] = value
+	}
+	for key, value := range configMap {
+		def, ok := t.Properties[key]
+		if !ok {
+			if _, inState := stateMap[key]; inState && t.AdditionalProperties != nil && t.AdditionalProperties.Type != nil && !IsInstanceAnnotation(key) {
+				res[key] = MergeWriteOnlyFields(t.AdditionalProperties.Type, stateMap[key], value)
+			}
+			continue
+		}
+		if def.IsWriteOnly() {
+			res[key] = value
+			continue
+		}
+		if _, inState := stateMap[key]; inState && def.Type != nil && def.Type.Type != nil {
+			res[key] = MergeWriteOnlyFields(def.Type.Type, stateMap[key], value)
+		}
+	}
+	return res
+}
+
+func (t *ObjectType) Redact(body interface{}) interface{} {
+	if t == nil || body == nil {
+		return body
+	}
+	if t.Sensitive {
+		return redactStrings(body)
+	}
+	bodyMap, ok := body.(map[string]interface{})
+	if !ok {
+		return body
+	}
+
+	res := make(map[string]interface{})
+	for key, value := range bodyMap {
+		if def, ok := t.Properties[key]; ok {
+			if def.Type != nil && def.Type.Type != nil {
+				res[key] = Redact(def.Type.Type, value)
+			} else {
+				res[key] = value
+			}
+			continue
+		}
+		if t.AdditionalProperties != nil && t.AdditionalProperties.Type != nil && !IsInstanceAnnotation(key) {
+			res[key] = Redact(t.AdditionalProperties.Type, value)
+			continue
+		}
+		res[key] = value
+	}
+	return res
+}
+
+// Sample returns the required properties, and also the writable properties which are not deprecated in the full mode.
+// The navigation properties are bound by `@odata.bind` in the full mode, and `@odata.type` and `@odata.id` are
+// generated if they're defined. The read-only properties and the other instance annotations are not generated. The
+// objects nested deeper than MaxDepth are empty, so that the required properties of the recursive types terminate.
+func (t *ObjectType) Sample(options *SampleOptions) interface{} {
+	options = options.orDefault()
+	if options.isTooDeep() {
+		return map[string]interface{}{}
+	}
+	keys := make([]string, 0)
+	for key := range t.Properties {
+		keys = append(keys, key)
+	}
+	// the keys are sorted, so that the random values are reproducible with the same seed
+	sort.Strings(keys)
+
+	out := make(map[string]interface{})
+	for _, key := range keys {
+		def := t.Properties[key]
+		if def.Type == nil || def.Type.Type == nil || def.IsReadOnly() {
+			continue
+		}
+		switch {
+		case key == "@odata.type":
+			if name := t.QualifiedName(); name != "" {
+				out[key] = "#" + name
+			}
+			continue
+		case key == "@odata.id":
+			if url, ok := options.sampleReference(nil); ok {
+				out[key] = url
+			}
+			continue
+		case IsInstanceAnnotation(key):
+			continue
+		}
+		if !def.IsRequired() && (!options.isFull() || def.Deprecated != nil) {
+			continue
+		}
+		if def.IsNavigation() {
+			if url, ok := options.sampleReference(navigationTargetType(def.Type.Type)); ok {
+				if _, isArray := def.Type.Type.(*ArrayType); isArray {
+					out[key+"@odata.bind"] = []interface{}{url}
+				} else {
+					out[key+"@odata.bind"] = url
+				}
+				continue
+			}
+			if !def.IsRequired() {
+				continue
+			}
+		}
+		out[key] = Sample(def.Type.Type, options.child())
+	}
+	return out
+}
+
+// QualifiedName returns the fully-qualified name of the type, e.g. `microsoft.graph.user`, it's the default value of
+// `@odata.type`. It returns an empty string if the type doesn't define the default value, e.g. the abstract base types.
+func (t *ObjectType) QualifiedName() string {
+	if t == nil {
+		return ""
+	}
+	def, ok := t.Properties["@odata.type"]
+	if !ok || def.Type == nil {
+		return ""
+	}
+	if stringType, ok := def.Type.Type.(*StringType); ok && stringType.Default != nil {
+		return strings.TrimPrefix(*stringType.Default, "#")
+	}
+	return ""
+}
+
 func (t *ObjectType) AsTypeBase() *TypeBase {
 	typeBase := TypeBase(t)
 	return &typeBase
@@ -155,6 +469,7 @@ type ObjectProperty struct {
 	Type        *TypeReference
 	Flags       []ObjectPropertyFlag
 	Description *string
+	Deprecated  *Deprecation
 }
 
 func (o *ObjectProperty) IsRequired() bool {
@@ -175,6 +490,15 @@ func (o *ObjectProperty) IsReadOnly() bool {
 	return false
 }
 
+func (o *ObjectProperty) IsWriteOnly() bool {
+	for _, value := range o.Flags {
+		if value == WriteOnly {
+			return true
+		}
+	}
+	return false
+}
+
 func (o *ObjectProperty) IsDeployTimeConstant() bool {
 	for _, value := range o.Flags {
 		if value == DeployTimeConstant {
@@ -184,6 +508,24 @@ func (o *ObjectProperty) IsDeployTimeConstant() bool {
 	return false
 }
 
+func (o *ObjectProperty) IsIdentifier() bool {
+	for _, value := range o.Flags {
+		if value == Identifier {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *ObjectProperty) IsNavigation() bool {
+	for _, value := range o.Flags {
+		if value == Navigation {
+			return true
+		}
+	}
+	return false
+}
+
 func (o *ObjectProperty) UnmarshalJSON(body []byte) error {
 	var m map[string]*json.RawMessage
 	err := json.Unmarshal(body, &m)
@@ -216,6 +558,15 @@ func (o *ObjectProperty) UnmarshalJSON(body []byte) error {
 				}
 				o.Flags = flags
 			}
+		case "deprecated":
+			if v != nil {
+				var deprecation Deprecation
+				err := json.Unmarshal(*v, &deprecation)
+				if err != nil {
+					return err
+				}
+				o.Deprecated = &deprecation
+			}
 		case "type":
 			if v != nil {
 				var typeRef TypeReference
@@ -246,8 +597,13 @@ const (
 	DeployTimeConstant ObjectPropertyFlag = 1 << 3
 
 	Identifier ObjectPropertyFlag = 1 << 4
+
+	Navigation ObjectPropertyFlag = 1 << 5
 )
 
+// CreateOnly marks the properties which can be specified on create but not updated, it's the same as DeployTimeConstant.
+const CreateOnly = DeployTimeConstant
+
 func PossibleObjectPropertyFlagValues() []ObjectPropertyFlag {
-	return []ObjectPropertyFlag{None, Required, ReadOnly, WriteOnly, DeployTimeConstant, Identifier}
+	return []ObjectPropertyFlag{None, Required, ReadOnly, WriteOnly, DeployTimeConstant, Identifier, Navigation}
 }
@@ -0,0 +1,794 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func Test_NewTypeBaseFromOpenAPISchemaAdditionalProperties(t *testing.T) {
+	hasAdditionalProperties := true
+	noAdditionalProperties := false
+
+	cases := []struct {
+		additionalProperties openapi3.AdditionalProperties
+		expected             string
+	}{
+		{
+			additionalProperties: openapi3.AdditionalProperties{},
+			expected:             "",
+		},
+		{
+			additionalProperties: openapi3.AdditionalProperties{Has: &noAdditionalProperties},
+			expected:             "",
+		},
+		{
+			additionalProperties: openapi3.AdditionalProperties{Has: &hasAdditionalProperties},
+			expected:             "any",
+		},
+		{
+			additionalProperties: openapi3.AdditionalProperties{Schema: openapi3.NewStringSchema().NewRef()},
+			expected:             "string",
+		},
+	}
+
+	for _, c := range cases {
+		schema := openapi3.NewObjectSchema().WithProperty("displayName", openapi3.NewStringSchema())
+		schema.AdditionalProperties = c.additionalProperties
+		typeBase := NewTypeBaseFromOpenAPISchema(schema, make(map[*openapi3.Schema]*TypeBase))
+		if typeBase == nil {
+			t.Errorf("expect an object type but got nil for %+v", c.additionalProperties)
+			continue
+		}
+		objectType, ok := (*typeBase).(*ObjectType)
+		if !ok {
+			t.Errorf("expect an object type but got %T for %+v", *typeBase, c.additionalProperties)
+			continue
+		}
+		actual := ""
+		if objectType.AdditionalProperties != nil {
+			actual = typeSummary(objectType.AdditionalProperties.Type)
+		}
+		if actual != c.expected {
+			t.Errorf("expect additional properties %q but got %q for %+v", c.expected, actual, c.additionalProperties)
+		}
+	}
+}
+
+func Test_ObjectTypeValidateInstanceAnnotations(t *testing.T) {
+	objectType := &ObjectType{
+		Type: "object",
+		Name: "application",
+		Properties: map[string]ObjectProperty{
+			"@odata.type": {
+				Type:  &TypeReference{Type: &StringType{Type: "string"}},
+				Flags: []ObjectPropertyFlag{Required},
+			},
+			"id": {
+				Type:  &TypeReference{Type: &StringType{Type: "string"}},
+				Flags: []ObjectPropertyFlag{ReadOnly},
+			},
+			"displayName": {
+				Type: &TypeReference{Type: &StringType{Type: "string"}},
+			},
+		},
+	}
+
+	cases := []struct {
+		body        map[string]interface{}
+		expectError bool
+	}{
+		{
+			body: map[string]interface{}{
+				"displayName": "foo",
+			},
+			expectError: false,
+		},
+		{
+			body: map[string]interface{}{
+				"displayName":               "foo",
+				"displayName@odata.context": "bar",
+			},
+			expectError: false,
+		},
+		{
+			body: map[string]interface{}{
+				"@odata.etag": "W/\"1\"",
+			},
+			expectError: false,
+		},
+		{
+			body: map[string]interface{}{
+				"@odata.etag": 1,
+			},
+			expectError: true,
+		},
+		{
+			body: map[string]interface{}{
+				"unknown@odata.context": "bar",
+			},
+			expectError: true,
+		},
+		{
+			body: map[string]interface{}{
+				"unknown": "bar",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, c := range cases {
+		errors := objectType.Validate(c.body, "")
+		if c.expectError != (len(errors) != 0) {
+			t.Errorf("expect error %v but got %v for body %v", c.expectError, errors, c.body)
+		}
+	}
+}
+
+func Test_ObjectTypeValidateAdditionalProperties(t *testing.T) {
+	objectType := &ObjectType{
+		Type: "object",
+		Properties: map[string]ObjectProperty{
+			"displayName": {
+				Type: &TypeReference{Type: &StringType{Type: "string"}},
+			},
+		},
+		AdditionalProperties: &TypeReference{Type: &StringType{Type: "string"}},
+	}
+
+	cases := []struct {
+		body        map[string]interface{}
+		expectError bool
+	}{
+		{
+			body:        map[string]interface{}{"dynamic": "value"},
+			expectError: false,
+		},
+		{
+			body:        map[string]interface{}{"displayName": "foo", "dynamic": "value"},
+			expectError: false,
+		},
+		{
+			body:        map[string]interface{}{"dynamic": true},
+			expectError: true,
+		},
+		{
+			body:        map[string]interface{}{"dynamic@odata.type": "#Int64"},
+			expectError: false,
+		},
+	}
+
+	for _, c := range cases {
+		errors := objectType.Validate(c.body, "")
+		if c.expectError != (len(errors) != 0) {
+			t.Errorf("expect error %v but got %v for body %v", c.expectError, errors, c.body)
+		}
+	}
+}
+
+func Test_ObjectPropertyUnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected ObjectProperty
+	}{
+		{
+			input: `{"flags": 3, "description": "The name."}`,
+			expected: ObjectProperty{
+				Flags:       []ObjectPropertyFlag{Required, ReadOnly},
+				Description: func() *string { v := "The name."; return &v }(),
+			},
+		},
+		{
+			input: `{"deprecated": {"date": "2024-01-01", "removalDate": "2025-01-01", "version": "2024-01/Widgets", "description": "Use displayName instead."}}`,
+			expected: ObjectProperty{
+				Deprecated: &Deprecation{Date: "2024-01-01", RemovalDate: "2025-01-01", Version: "2024-01/Widgets", Description: "Use displayName instead."},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		var actual ObjectProperty
+		if err := json.Unmarshal([]byte(c.input), &actual); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expect %v but got %v for %s", c.expected, actual, c.input)
+		}
+	}
+}
+
+func Test_ObjectTypeValidateODataBind(t *testing.T) {
+	directoryObject := &ObjectType{
+		Type: "object",
+		Name: "directoryObject",
+		Properties: map[string]ObjectProperty{
+			"id": {
+				Type:  &TypeReference{Type: &StringType{Type: "string"}},
+				Flags: []ObjectPropertyFlag{ReadOnly},
+			},
+		},
+	}
+	objectType := &ObjectType{
+		Type: "object",
+		Name: "application",
+		Properties: map[string]ObjectProperty{
+			"displayName": {
+				Type: &TypeReference{Type: &StringType{Type: "string"}},
+			},
+			"owners": {
+				Type: &TypeReference{Type: &ArrayType{
+					Type:     "array",
+					ItemType: &TypeReference{Type: directoryObject},
+				}},
+				Flags: []ObjectPropertyFlag{Navigation},
+			},
+			"manager": {
+				Type:  &TypeReference{Type: directoryObject},
+				Flags: []ObjectPropertyFlag{Navigation},
+			},
+		},
+	}
+
+	cases := []struct {
+		body        map[string]interface{}
+		expectError bool
+	}{
+		{
+			body: map[string]interface{}{
+				"owners@odata.bind":  []interface{}{"https://graph.microsoft.com/v1.0/directoryObjects/id"},
+				"manager@odata.bind": "https://graph.microsoft.com/v1.0/directoryObjects/id",
+			},
+			expectError: false,
+		},
+		{
+			body: map[string]interface{}{
+				"owners@odata.bind": "https://graph.microsoft.com/v1.0/directoryObjects/id",
+			},
+			expectError: true,
+		},
+		{
+			body: map[string]interface{}{
+				"manager@odata.bind": []interface{}{"https://graph.microsoft.com/v1.0/directoryObjects/id"},
+			},
+			expectError: true,
+		},
+		{
+			body: map[string]interface{}{
+				"displayName@odata.bind": "https://graph.microsoft.com/v1.0/directoryObjects/id",
+			},
+			expectError: true,
+		},
+		{
+			body: map[string]interface{}{
+				"members@odata.bind": []interface{}{"https://graph.microsoft.com/v1.0/directoryObjects/id"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, c := range cases {
+		errors := objectType.Validate(c.body, "")
+		if c.expectError != (len(errors) != 0) {
+			t.Errorf("expect error %v but got %v for body %v", c.expectError, errors, c.body)
+		}
+	}
+}
+
+func Test_ObjectTypeWriteOnlyFields(t *testing.T) {
+	objectType := &ObjectType{
+		Type: "object",
+		Name: "user",
+		Properties: map[string]ObjectProperty{
+			"id": {
+				Type:  &TypeReference{Type: &StringType{Type: "string"}},
+				Flags: []ObjectPropertyFlag{ReadOnly},
+			},
+			"displayName": {
+				Type: &TypeReference{Type: &StringType{Type: "string"}},
+			},
+			"passwordProfile": {
+				Type: &TypeReference{Type: &ObjectType{
+					Type: "object",
+					Properties: map[string]ObjectProperty{
+						"password": {
+							Type:  &TypeReference{Type: &StringType{Type: "string"}},
+							Flags: []ObjectPropertyFlag{WriteOnly},
+						},
+						"forceChangePasswordNextSignIn": {
+							Type: &TypeReference{Type: &BooleanType{Type: "boolean"}},
+						},
+					},
+				}},
+			},
+		},
+	}
+
+	cases := []struct {
+		state          interface{}
+		config         interface{}
+		expectFiltered interface{}
+		expectMerged   interface{}
+	}{
+		{
+			state: map[string]interface{}{
+				"id":          "00000000-0000-0000-0000-000000000000",
+				"displayName": "foo",
+				"passwordProfile": map[string]interface{}{
+					"forceChangePasswordNextSignIn": true,
+				},
+			},
+			config: map[string]interface{}{
+				"displayName": "foo",
+				"passwordProfile": map[string]interface{}{
+					"password":                      "secret",
+					"forceChangePasswordNextSignIn": true,
+				},
+			},
+			expectFiltered: map[string]interface{}{
+				"displayName": "foo",
+				"passwordProfile": map[string]interface{}{
+					"forceChangePasswordNextSignIn": true,
+				},
+			},
+			expectMerged: map[string]interface{}{
+				"id":          "00000000-0000-0000-0000-000000000000",
+				"displayName": "foo",
+				"passwordProfile": map[string]interface{}{
+					"password":                      "secret",
+					"forceChangePasswordNextSignIn": true,
+				},
+			},
+		},
+		{
+			state: map[string]interface{}{
+				"displayName": "foo",
+			},
+			config: map[string]interface{}{
+				"displayName": "foo",
+			},
+			expectFiltered: map[string]interface{}{
+				"displayName": "foo",
+			},
+			expectMerged: map[string]interface{}{
+				"displayName": "foo",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		if actual := objectType.FilterWriteOnlyFields(c.config); !reflect.DeepEqual(actual, c.expectFiltered) {
+			t.Errorf("expect %v but got %v", c.expectFiltered, actual)
+		}
+		if actual := objectType.MergeWriteOnlyFields(c.state, c.config); !reflect.DeepEqual(actual, c.expectMerged) {
+			t.Errorf("expect %v but got %v", c.expectMerged, actual)
+		}
+	}
+}
+
+func Test_ObjectTypeApplyDefaults(t *testing.T) {
+	defaultFalse := false
+	defaultType := "#microsoft.graph.application"
+	defaultVersion := 2.0
+	objectType := &ObjectType{
+		Type: "object",
+		Name: "application",
+		Properties: map[string]ObjectProperty{
+			"@odata.type": {
+				Type:  &TypeReference{Type: &StringType{Type: "string", Default: &defaultType}},
+				Flags: []ObjectPropertyFlag{Required},
+			},
+			"displayName": {
+				Type: &TypeReference{Type: &StringType{Type: "string"}},
+			},
+			"isFallbackPublicClient": {
+				Type: &TypeReference{Type: &BooleanType{Type: "boolean", Default: &defaultFalse}},
+			},
+			"web": {
+				Type: &TypeReference{Type: &ObjectType{
+					Type: "object",
+					Properties: map[string]ObjectProperty{
+						"implicitGrantSettings": {
+							Type: &TypeReference{Type: &ObjectType{
+								Type:    "object",
+								Default: map[string]interface{}{"enableIdTokenIssuance": false},
+							}},
+						},
+					},
+				}},
+			},
+			"tags": {
+				Type: &TypeReference{Type: &ArrayType{
+					Type:     "array",
+					ItemType: &TypeReference{Type: &StringType{Type: "string"}},
+					Default:  []interface{}{"default"},
+				}},
+			},
+			"info": {
+				Type: &TypeReference{Type: &UnionType{
+					Type: "union",
+					Elements: []*TypeReference{
+						{Type: &ObjectType{
+							Type:       "object",
+							Properties: map[string]ObjectProperty{"logoUrl": {Type: &TypeReference{Type: &StringType{Type: "string"}}}},
+							Default:    map[string]interface{}{"logoUrl": "https://example.com"},
+						}},
+						{Type: &ObjectType{Type: "object"}},
+					},
+				}},
+			},
+			"api": {
+				Type: &TypeReference{Type: &ObjectType{
+					Type: "object",
+					Properties: map[string]ObjectProperty{
+						"requestedAccessTokenVersion": {
+							Type: &TypeReference{Type: &NumberType{Type: "number", Default: &defaultVersion}},
+						},
+					},
+				}},
+				Flags: []ObjectPropertyFlag{Required},
+			},
+		},
+	}
+
+	cases := []struct {
+		body     interface{}
+		expected interface{}
+	}{
+		{
+			body: map[string]interface{}{
+				"displayName": "foo",
+				"web":         map[string]interface{}{},
+			},
+			expected: map[string]interface{}{
+				"displayName":            "foo",
+				"isFallbackPublicClient": false,
+				"web": map[string]interface{}{
+					"implicitGrantSettings": map[string]interface{}{"enableIdTokenIssuance": false},
+				},
+				"tags": []interface{}{"default"},
+				"info": map[string]interface{}{"logoUrl": "https://example.com"},
+				// the missing required objects aren't created
+			},
+		},
+		{
+			body: map[string]interface{}{
+				"isFallbackPublicClient": true,
+				"tags":                   []interface{}{},
+				"info":                   map[string]interface{}{"logoUrl": "https://example.org"},
+				"api":                    map[string]interface{}{"requestedAccessTokenVersion": 1.0},
+			},
+			expected: map[string]interface{}{
+				"isFallbackPublicClient": true,
+				"tags":                   []interface{}{},
+				"info":                   map[string]interface{}{"logoUrl": "https://example.org"},
+				"api":                    map[string]interface{}{"requestedAccessTokenVersion": 1.0},
+			},
+		},
+		{
+			body: map[string]interface{}{
+				"web": map[string]interface{}{},
+				"api": map[string]interface{}{},
+			},
+			expected: map[string]interface{}{
+				"isFallbackPublicClient": false,
+				"web": map[string]interface{}{
+					"implicitGrantSettings": map[string]interface{}{"enableIdTokenIssuance": false},
+				},
+				"tags": []interface{}{"default"},
+				"info": map[string]interface{}{"logoUrl": "https://example.com"},
+				"api":  map[string]interface{}{"requestedAccessTokenVersion": 2.0},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		if actual := objectType.ApplyDefaults(c.body); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expect %v but got %v", c.expected, actual)
+		}
+	}
+}
+
+func Test_CheckDeprecations(t *testing.T) {
+	objectType := &ObjectType{
+		Type: "object",
+		Name: "application",
+		Properties: map[string]ObjectProperty{
+			"displayName": {
+				Type: &TypeReference{Type: &StringType{Type: "string"}},
+			},
+			"legacyName": {
+				Type:       &TypeReference{Type: &StringType{Type: "string"}},
+				Deprecated: &Deprecation{RemovalDate: "2025-01-01", Description: "use displayName instead"},
+			},
+			"signInAudience": {
+				Type: &TypeReference{Type: &StringType{
+					Type:           "string",
+					Enum:           []string{"AzureADMyOrg", "AzureADMultipleOrgs", "PersonalMicrosoftAccount"},
+					DeprecatedEnum: map[string]*Deprecation{"PersonalMicrosoftAccount": {}},
+				}},
+			},
+		},
+	}
+	resourceType := &ResourceType{
+		Type:       "resource",
+		Body:       &TypeReference{Type: objectType},
+		Deprecated: &Deprecation{},
+	}
+
+	cases := []struct {
+		body         map[string]interface{}
+		expectPaths  []string
+		expectErrors int
+	}{
+		{
+			body: map[string]interface{}{
+				"displayName":    "foo",
+				"legacyName":     "foo",
+				"signInAudience": "PersonalMicrosoftAccount",
+			},
+			expectPaths: []string{"", ".legacyName", ".signInAudience"},
+		},
+		{
+			body: map[string]interface{}{
+				"displayName":    "foo",
+				"signInAudience": "AzureADMyOrg",
+			},
+			expectPaths: []string{""},
+		},
+	}
+
+	for _, c := range cases {
+		errors, warnings := resourceType.ValidateWithWarnings(c.body, "")
+		if len(errors) != c.expectErrors {
+			t.Errorf("expect %d errors but got %v", c.expectErrors, errors)
+		}
+		paths := make([]string, 0)
+		for _, warning := range warnings {
+			paths = append(paths, warning.Path)
+		}
+		sort.Strings(paths)
+		if !reflect.DeepEqual(paths, c.expectPaths) {
+			t.Errorf("expect warnings for %v but got %v", c.expectPaths, paths)
+		}
+		if expected, actual := resourceType.Diagnose(c.body, "", nil).Deprecations(), CheckDeprecations(resourceType, c.body, ""); len(expected) != len(actual) {
+			t.Errorf("expect CheckDeprecations to return the deprecations of Diagnose %v but got %v", expected, actual)
+		}
+	}
+}
+
+func Test_Diagnose(t *testing.T) {
+	objectType := &ObjectType{
+		Type: "object",
+		Name: "application",
+		Properties: map[string]ObjectProperty{
+			"id": {
+				Type:  &TypeReference{Type: &StringType{Type: "string"}},
+				Flags: []ObjectPropertyFlag{ReadOnly},
+			},
+			"mailNickname": {
+				Type: &TypeReference{Type: &StringType{Type: "string", Pattern: "(invalid"}},
+			},
+			"signInAudience": {
+				Type: &TypeReference{Type: &StringType{
+					Type: "string",
+					Enum: []string{"AzureADMyOrg", "unknownFutureValue", "AzureADandPersonalMicrosoftAccount"},
+				}},
+			},
+		},
+	}
+
+	cases := []struct {
+		body           map[string]interface{}
+		expectErrors   int
+		expectWarnings int
+		expectInfos    int
+	}{
+		{
+			body: map[string]interface{}{
+				"id":             "00000000-0000-0000-0000-000000000000",
+				"mailNickname":   "foo",
+				"signInAudience": "AzureADandPersonalMicrosoftAccount",
+			},
+			expectErrors:   1,
+			expectWarnings: 1,
+			expectInfos:    1,
+		},
+		{
+			body: map[string]interface{}{
+				"mailNickname": "foo",
+			},
+			expectErrors:   0,
+			expectWarnings: 1,
+		},
+	}
+
+	for _, c := range cases {
+		diagnostics := objectType.Diagnose(c.body, "", nil)
+		if actual := len(diagnostics.Errors()); actual != c.expectErrors {
+			t.Errorf("expect %d errors but got %d: %v", c.expectErrors, actual, diagnostics)
+		}
+		if actual := len(diagnostics.Warnings()); actual != c.expectWarnings {
+			t.Errorf("expect %d warnings but got %d: %v", c.expectWarnings, actual, diagnostics)
+		}
+		if actual := len(diagnostics.BySeverity(SeverityInfo)); actual != c.expectInfos {
+			t.Errorf("expect %d infos but got %d: %v", c.expectInfos, actual, diagnostics)
+		}
+	}
+}
+
+func Test_ValidationOptions(t *testing.T) {
+	objectType := &ObjectType{
+		Type: "object",
+		Name: "application",
+		Properties: map[string]ObjectProperty{
+			"name": {
+				Type:  &TypeReference{Type: &StringType{Type: "string"}},
+				Flags: []ObjectPropertyFlag{Required},
+			},
+			"mailNickname": {
+				Type:  &TypeReference{Type: &StringType{Type: "string", Pattern: "^[a-z]+$"}},
+				Flags: []ObjectPropertyFlag{Required},
+			},
+		},
+	}
+
+	cases := []struct {
+		body           map[string]interface{}
+		options        *ValidationOptions
+		expectErrors   int
+		expectWarnings int
+	}{
+		{
+			body:         map[string]interface{}{"mailNickname": "foo"},
+			options:      nil,
+			expectErrors: 0,
+		},
+		{
+			body:         map[string]interface{}{"mailNickname": "foo"},
+			options:      &ValidationOptions{},
+			expectErrors: 0,
+		},
+		{
+			body:         map[string]interface{}{"mailNickname": "foo"},
+			options:      &ValidationOptions{SkipRequiredProperties: []string{}},
+			expectErrors: 1,
+		},
+		{
+			body:         map[string]interface{}{},
+			options:      &ValidationOptions{SkipRequired: true},
+			expectErrors: 0,
+		},
+		{
+			body:         map[string]interface{}{"name": "foo", "mailNickname": ""},
+			options:      nil,
+			expectErrors: 0,
+		},
+		{
+			body:         map[string]interface{}{"name": "foo", "mailNickname": ""},
+			options:      &ValidationOptions{},
+			expectErrors: 0,
+		},
+		{
+			body:         map[string]interface{}{"name": "foo", "mailNickname": ""},
+			options:      &ValidationOptions{ValidateEmptyStrings: true},
+			expectErrors: 1,
+		},
+		{
+			body:         map[string]interface{}{"name": "foo", "mailNickname": UnknownValue},
+			options:      &ValidationOptions{},
+			expectErrors: 0,
+		},
+		{
+			body:           map[string]interface{}{"name": "foo", "mailNickname": "foo", "unknown": "foo"},
+			options:        &ValidationOptions{UnknownProperties: UnknownPropertyWarning},
+			expectErrors:   0,
+			expectWarnings: 1,
+		},
+		{
+			body:         map[string]interface{}{"name": "foo", "mailNickname": "foo", "unknown": "foo"},
+			options:      &ValidationOptions{UnknownProperties: UnknownPropertyIgnore},
+			expectErrors: 0,
+		}, {
+			body:         map[string]interface{}{"name": "foo", "mailNickname": "foo", "unknown@odata.bind": "https://graph.microsoft.com/v1.0/users/1"},
+			options:      nil,
+			expectErrors: 1,
+		},
+		{
+			body:           map[string]interface{}{"name": "foo", "mailNickname": "foo", "unknown@odata.bind": "https://graph.microsoft.com/v1.0/users/1"},
+			options:        &ValidationOptions{UnknownProperties: UnknownPropertyWarning},
+			expectErrors:   0,
+			expectWarnings: 1,
+		},
+		{
+			body:         map[string]interface{}{"name": "foo", "mailNickname": "foo", "unknown@odata.bind": "https://graph.microsoft.com/v1.0/users/1"},
+			options:      &ValidationOptions{UnknownProperties: UnknownPropertyIgnore},
+			expectErrors: 0,
+		},
+	}
+
+	for _, c := range cases {
+		diagnostics := objectType.Diagnose(c.body, "", c.options)
+		if actual := len(diagnostics.Errors()); actual != c.expectErrors {
+			t.Errorf("expect %d errors but got %d: %v", c.expectErrors, actual, diagnostics)
+		}
+		if actual := len(diagnostics.Warnings()); actual != c.expectWarnings {
+			t.Errorf("expect %d warnings but got %d: %v", c.expectWarnings, actual, diagnostics)
+		}
+	}
+}
+
+// externalType implements the methods of TypeBase only, like the types defined outside of this package.
+type externalType struct {
+	errors []error
+}
+
+func (t *externalType) AsTypeBase() *TypeBase {
+	typeBase := TypeBase(t)
+	return &typeBase
+}
+
+func (t *externalType) FilterConfigurableFields(body interface{}) interface{} {
+	return body
+}
+
+func (t *externalType) FilterReadOnlyFields(body interface{}) interface{} {
+	return body
+}
+
+func (t *externalType) Validate(body interface{}, path string) []error {
+	return t.errors
+}
+
+func Test_TypeBaseFunctions(t *testing.T) {
+	cases := []struct {
+		t            TypeBase
+		body         interface{}
+		expectErrors int
+		expectSample bool
+	}{
+		{
+			t:            &externalType{},
+			body:         "foo",
+			expectErrors: 0,
+			expectSample: false,
+		},
+		{
+			t:            &externalType{errors: []error{ErrorMismatch("", "string", "number")}},
+			body:         1,
+			expectErrors: 1,
+			expectSample: false,
+		},
+		{
+			t:            &StringType{Type: "string"},
+			body:         "foo",
+			expectErrors: 0,
+			expectSample: true,
+		},
+		{
+			t:            &ObjectType{Type: "object"},
+			body:         map[string]interface{}{},
+			expectErrors: 0,
+			expectSample: true,
+		},
+	}
+
+	for _, c := range cases {
+		if actual := len(Diagnose(c.t, c.body, "", nil).Errors()); actual != c.expectErrors {
+			t.Errorf("expect %d errors but got %d for %T", c.expectErrors, actual, c.t)
+		}
+		if errors := c.t.Validate(c.body, ""); c.expectErrors == 0 && errors != nil {
+			t.Errorf("expect nil errors but got %#v for %T", errors, c.t)
+		}
+		for _, actual := range []interface{}{ApplyDefaults(c.t, c.body), FilterWriteOnlyFields(c.t, c.body), MergeWriteOnlyFields(c.t, c.body, c.body), Redact(c.t, c.body)} {
+			if !reflect.DeepEqual(actual, c.body) {
+				t.Errorf("expect %v but got %v for %T", c.body, actual, c.t)
+			}
+		}
+		if actual := Sample(c.t, nil) != nil; actual != c.expectSample {
+			t.Errorf("expect sample %v but got %v for %T", c.expectSample, actual, c.t)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"regexp"
+	"strings"
+)
+
+// InstanceAnnotation is an OData instance annotation key, e.g. `@odata.type` or `owners@odata.bind`.
+type InstanceAnnotation struct {
+	// Property is the annotated property, it's empty when the annotation applies to the object itself.
+	Property string
+	// Term is the namespace qualified annotation term, e.g. `odata.type`.
+	Term string
+}
+
+var instanceAnnotationTermRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)+$`)
+
+// ParseInstanceAnnotation parses the key as an OData instance annotation, it returns false if the key isn't an annotation.
+func ParseInstanceAnnotation(key string) (*InstanceAnnotation, bool) {
+	index := strings.LastIndex(key, "@")
+	if index < 0 {
+		return nil, false
+	}
+	property, term := key[:index], key[index+1:]
+	if strings.Contains(property, "@") || !instanceAnnotationTermRegex.MatchString(term) {
+		return nil, false
+	}
+	return &InstanceAnnotation{
+		Property: property,
+		Term:     term,
+	}, true
+}
+
+// IsInstanceAnnotation returns true if the key is an OData instance annotation.
+func IsInstanceAnnotation(key string) bool {
+	_, ok := ParseInstanceAnnotation(key)
+	return ok
+}
+
+// wellKnownInstanceAnnotations defines the value types of the OData annotations that Microsoft Graph accepts or returns.
+// The value of any other annotation is not validated.
+var wellKnownInstanceAnnotations = map[string]TypeBase{
+	"odata.context":          &StringType{Type: "string"},
+	"odata.count":            &NumberType{Type: "number"},
+	"odata.deltaLink":        &StringType{Type: "string"},
+	"odata.editLink":         &StringType{Type: "string"},
+	"odata.etag":             &StringType{Type: "string"},
+	"odata.id":               &StringType{Type: "string"},
+	"odata.mediaContentType": &StringType{Type: "string"},
+	"odata.mediaEditLink":    &StringType{Type: "string"},
+	"odata.mediaReadLink":    &StringType{Type: "string"},
+	"odata.navigationLink":   &StringType{Type: "string"},
+	"odata.nextLink":         &StringType{Type: "string"},
+	"odata.readLink":         &StringType{Type: "string"},
+	"odata.type":             &StringType{Type: "string"},
+}
+
+func (a *InstanceAnnotation) Validate(value interface{}, path string) []error {
+	if a == nil {
+		return nil
+	}
+	if t, ok := wellKnownInstanceAnnotations[a.Term]; ok {
+		return t.Validate(value, path)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package types
+
+import (
+	"fmt"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ReferenceResolver resolves the entity URLs used in `@odata.bind` annotations.
+type ReferenceResolver interface {
+	// ResolveReference returns the type of the entity addressed by the URL.
+	ResolveReference(url string) (TypeBase, error)
+}
+
+// validateODataBindReferences checks that every URL in the `@odata.bind` annotations of the body addresses
+// an entity whose type matches the type of the annotated navigation property.
+func validateODataBindReferences(t TypeBase, body interface{}, path string, resolver ReferenceResolver) []error {
+	if t == nil || body == nil || resolver == nil {
+		return nil
+	}
+	errors := make([]error, 0)
+	switch v := t.(type) {
+	case *ObjectType:
+		bodyMap, ok := body.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for key, value := range bodyMap {
+			if annotation, ok := ParseInstanceAnnotation(key); ok {
+				if annotation.Term != "odata.bind" {
+					continue
+				}
+				def, ok := v.Properties[annotation.Property]
+				if !ok || !def.IsNavigation() || def.Type == nil {
+					continue
+				}
+				target := navigationTargetType(def.Type.Type)
+				urls := make([]interface{}, 0)
+				switch value := value.(type) {
+				case []interface{}:
+					urls = value
+				case string:
+					urls = append(urls, value)
+				}
+				for index, url := range urls {
+					urlPath := path + "." + key
+					if _, isArray := value.([]interface{}); isArray {
+						urlPath = fmt.Sprintf("%s.%d", urlPath, index)
+					}
+					if url, ok := url.(string); ok {
+						errors = append(errors, validateODataBindReference(url, target, urlPath, resolver)...)
+					}
+				}
+				continue
+			}
+			if def, ok := v.Properties[key]; ok && def.Type != nil {
+				errors = append(errors, validateODataBindReferences(def.Type.Type, value, path+"."+key, resolver)...)
+			}
+		}
+	case *ArrayType:
+		bodyArray, ok := body.([]interface{})
+		if !ok || v.ItemType == nil {
+			return nil
+		}
+		for index, value := range bodyArray {
+			errors = append(errors, validateODataBindReferences(v.ItemType.Type, value, fmt.Sprintf("%s.%d", path, index), resolver)...)
+		}
+	case *UnionType:
+		for _, element := range v.Elements {
+			if element.Type == nil || Diagnose(element.Type, body, path, nil).HasErrors() {
+				continue
+			}
+			return validateODataBindReferences(element.Type, body, path, resolver)
+		}
+	case *ResourceType:
+		if v.Body != nil {
+			return validateODataBindReferences(v.Body.Type, body, path, resolver)
+		}
+	}
+	return errors
+}
+
+func validateODataBindReference(url string, target TypeBase, path string, resolver ReferenceResolver) []error {
+	resolved, err := resolver.ResolveReference(url)
+	if err != nil {
+		return []error{ErrorCommon(path, fmt.Sprintf("the reference `%s` is invalid: %v", url, err))}
+	}
+	if !isAssignableTo(resolved, target) {
+		name := "entity"
+		if targetObject, ok := target.(*ObjectType); ok && targetObject.Name != "" {
+			name = targetObject.Name
+		}
+		return []error{ErrorCommon(path, fmt.Sprintf("the reference `%s` doesn't address a `%s`", url, name))}
+	}
+	return nil
+}
+
+// navigationTargetType returns the entity type of a single or collection navigation property.
+func navigationTargetType(t TypeBase) TypeBase {
+	switch v := t.(type) {
+	case *ArrayType:
+		if v.ItemType != nil {
+			return navigationTargetType(v.ItemType.Type)
+		}
+	case *UnionType:
+		// single navigation properties are defined as anyOf the entity type and a nullable object
+		for _, element := range v.Elements {
+			if element.Type == nil {
+				continue
+			}
+			if objectType, ok := element.Type.(*ObjectType); ok && len(objectType.Properties) != 0 {
+				return objectType
+			}
+		}
+	case *ObjectType:
+		return v
+	}
+	return nil
+}
+
+// isAssignableTo returns true if the entity type is the target type or derived from it, the types are compared by the
+// fully-qualified names and the base types of the entity type. The target types which don't have fully-qualified
+// names, e.g. `entity`, are compared by their names.
+func isAssignableTo(t TypeBase, target TypeBase) bool {
+	targetObject, ok := target.(*ObjectType)
+	if !ok || targetObject == nil {
+		return true
+	}
+	object, ok := t.(*ObjectType)
+	if !ok || object == nil {
+		return false
+	}
+	if object == targetObject {
+		return true
+	}
+	targetName := targetObject.QualifiedName()
+	names := append([]string{object.QualifiedName(), object.Name}, object.BaseTypes...)
+	for _, name := range names {
+		switch {
+		case name == "":
+		case targetName != "":
+			if name == targetName {
+				return true
+			}
+		case targetObject.Name != "":
+			if name[strings.LastIndex(name, ".")+1:] == targetObject.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// referenceSampler generates the URLs of the entities which are assignable to the target types.
+type referenceSampler interface {
+	sampleReference(target TypeBase, options *SampleOptions) (string, bool)
+}
+
+var _ ReferenceResolver = &schemaReferenceResolver{}
+var _ referenceSampler = &schemaReferenceResolver{}
+
+// schemaReferenceResolver resolves the references against the paths defined in the OpenAPI document of the api version.
+type schemaReferenceResolver struct {
+	index       *schemaIndex
+	cache       *typeCache
+	apiVersion  string
+	apiVersions []string
+	// entitySets are the entity sets of the sampled references keyed by the target types, they're guarded by the mutex.
+	mutex      sync.Mutex
+	entitySets map[TypeBase]string
+}
+
+func (r *schemaReferenceResolver) ResolveReference(url string) (TypeBase, error) {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return nil, err
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for _, apiVersion := range r.apiVersions {
+		if segments[0] != apiVersion {
+			continue
+		}
+		if apiVersion != r.apiVersion {
+			return nil, fmt.Errorf("the reference addresses api-version `%s` but the resource is in api-version `%s`", apiVersion, r.apiVersion)
+		}
+		segments = segments[1:]
+		break
+	}
+	if len(segments) == 0 || segments[0] == "" {
+		return nil, fmt.Errorf("the path is empty")
+	}
+
+	item := r.findPath(segments)
+	if item == nil || item.get == nil || item.get.response == nil {
+		return nil, fmt.Errorf("no entity is addressed by the path `/%s`", strings.Join(segments, "/"))
+	}
+	entityType := r.cache.convert(item.get.response)
+	if entityType == nil {
+		return nil, fmt.Errorf("no entity is addressed by the path `/%s`", strings.Join(segments, "/"))
+	}
+	return *entityType, nil
+}
+
+// sampleReference returns the URL of an entity in the first entity set whose entities are assignable to the target.
+func (r *schemaReferenceResolver) sampleReference(target TypeBase, options *SampleOptions) (string, bool) {
+	r.mutex.Lock()
+	entitySet, ok := r.entitySets[target]
+	r.mutex.Unlock()
+	if !ok && r.index != nil {
+		for _, candidate := range r.index.entitySets {
+			resolved, err := r.ResolveReference(candidate + "/" + formatSamples["uuid"])
+			if err == nil && isAssignableTo(resolved, target) {
+				entitySet = candidate
+				break
+			}
+		}
+		r.mutex.Lock()
+		if r.entitySets == nil {
+			r.entitySets = make(map[TypeBase]string)
+		}
+		r.entitySets[target] = entitySet
+		r.mutex.Unlock()
+	}
+	if entitySet == "" {
+		return "", false
+	}
+	return fmt.Sprintf("https://graph.microsoft.com/%s%s/%s", r.apiVersion, entitySet, options.sampleUUID()), true
+}
+
+// findPath returns the path which matches the segments, each segment is looked up in the normalized paths as a literal,
+// a variable or a key like `applications(appId='{}')`, the path with the most literal segments wins. The prefixes which
+// don't match any path are skipped, so that it doesn't try every combination.
+func (r *schemaReferenceResolver) findPath(segments []string) *pathIndex {
+	if r.index == nil {
+		return nil
+	}
+	var out *pathIndex
+	score := -1
+	var visit func(i int, prefix string, literals int)
+	visit = func(i int, prefix string, literals int) {
+		if i == len(segments) {
+			if item := r.index.normalizedPaths[prefix]; item != nil && literals > score {
+				out, score = item, literals
+			}
+			return
+		}
+		if prefix != "" && !r.index.normalizedPrefixes[prefix] {
+			return
+		}
+		visit(i+1, prefix+"/"+segments[i], literals+1)
+		visit(i+1, prefix+"/{}", literals)
+		if key := keySegmentTemplate(segments[i]); key != segments[i] {
+			visit(i+1, prefix+"/"+key, literals)
+		}
+	}
+	visit(0, "", 0)
+	return out
+}
+
+var keyValueRegex = regexp.MustCompile(`=('[^']*'|[^,)']*)`)
+
+// keySegmentTemplate returns the normalized template of a key segment, e.g. `applications(appId='{}')` of
+// `applications(appId='00000000-0000-0000-0000-000000000000')`.
+func keySegmentTemplate(segment string) string {
+	if !strings.Contains(segment, "(") {
+		return segment
+	}
+	return keyValueRegex.ReplaceAllStringFunc(segment, func(value string) string {
+		if strings.HasPrefix(value, "='") {
+			return "='{}'"
+		}
+		return "={}"
+	})
+}
@@ -0,0 +1,230 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+type PermissionType string
+
+const (
+	// PermissionTypeDelegatedWork is the delegated permission of work or school accounts.
+	PermissionTypeDelegatedWork PermissionType = "DelegatedWork"
+
+	// PermissionTypeDelegatedPersonal is the delegated permission of personal Microsoft accounts.
+	PermissionTypeDelegatedPersonal PermissionType = "DelegatedPersonal"
+
+	// PermissionTypeApplication is the application permission.
+	PermissionTypeApplication PermissionType = "Application"
+)
+
+// OperationPermissions are the permissions required by an operation, any of the permissions grants the access.
+type OperationPermissions struct {
+	Method string
+	Url    string
+	// LeastPrivileged are the least privileged permissions, keyed by the permission type.
+	LeastPrivileged map[PermissionType][]string
+	// All are all permissions which grant the access, keyed by the permission type, the least privileged
+	// permissions are included.
+	All map[PermissionType][]string
+}
+
+// PermissionSet are the granted permissions keyed by the permission type, e.g. the delegated permissions and the
+// application permissions of an app registration.
+type PermissionSet map[PermissionType][]string
+
+// Allows returns true if any of the permissions in the permission set grants the access.
+func (p *OperationPermissions) Allows(permissions PermissionSet) bool {
+	if p == nil {
+		return false
+	}
+	for permissionType, granted := range permissions {
+		for _, required := range p.All[permissionType] {
+			for _, permission := range granted {
+				if strings.EqualFold(required, permission) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// ListResourcesByPermissions returns the resources whose primary operations are granted by the permission set, they're
+// POST for the collections and the references, and GET for the readable resources. It returns an error if the schema
+// or the permissions can't be loaded, e.g. `openapi/permissions.json` doesn't exist.
+func (r *MSGraphSchemaLoader) ListResourcesByPermissions(apiVersion string, permissions PermissionSet) ([]ResourceType, error) {
+	entry, err := r.getSchemaEntry(context.Background(), apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	if entry.permissionsErr != nil {
+		return nil, fmt.Errorf("the permissions of api-version %s are not available: %+v", apiVersion, entry.permissionsErr)
+	}
+	index := entry.index
+
+	out := make([]ResourceType, 0)
+	for _, resources := range [][]ResourceType{index.resources, index.readableResources} {
+		for _, resource := range resources {
+			method := "GET"
+			if resource.Kind != ResourceKindReadable {
+				method = "POST"
+			}
+			for _, operation := range resource.Permissions {
+				if operation.Method == method && operation.Url == resource.Url && operation.Allows(permissions) {
+					out = append(out, resource.copy())
+					break
+				}
+			}
+		}
+	}
+	sortResources(out)
+	return out, nil
+}
+
+func copyPermissionMap(input map[PermissionType][]string) map[PermissionType][]string {
+	if input == nil {
+		return nil
+	}
+	out := make(map[PermissionType][]string, len(input))
+	for permissionType, permissions := range input {
+		out[permissionType] = append([]string(nil), permissions...)
+	}
+	return out
+}
+
+// permissionIndex contains the permissions of the operations, keyed by the normalized lower case path and then the method.
+type permissionIndex map[string]map[string]*OperationPermissions
+
+// permissionsFile is the permissions file of https://github.com/microsoftgraph/msgraph-metadata/tree/master/permissions
+type permissionsFile struct {
+	Permissions map[string]struct {
+		PathSets []struct {
+			SchemeKeys []PermissionType `json:"schemeKeys"`
+			Methods    []string         `json:"methods"`
+			// Paths are the paths whose variables are `{id}`, the values are the flags like `least=DelegatedWork,Application`
+			Paths map[string]string `json:"paths"`
+		} `json:"pathSets"`
+	} `json:"permissions"`
+}
+
+// loadPermissions loads the permissions from `openapi/permissions.json` of the file system.
+func loadPermissions(fsys fs.FS) (permissionIndex, error) {
+	data, err := fs.ReadFile(fsys, "openapi/permissions.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read permissions: %+v", err)
+	}
+	var input permissionsFile
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse permissions: %+v", err)
+	}
+
+	out := make(permissionIndex)
+	for name, permission := range input.Permissions {
+		for _, pathSet := range permission.PathSets {
+			for path, flags := range pathSet.Paths {
+				least := leastPrivilegedPermissionTypes(flags)
+				key := permissionPathKey(path)
+				for _, method := range pathSet.Methods {
+					method = strings.ToUpper(strings.TrimSpace(method))
+					if out[key] == nil {
+						out[key] = make(map[string]*OperationPermissions)
+					}
+					operation := out[key][method]
+					if operation == nil {
+						operation = &OperationPermissions{
+							Method:          method,
+							LeastPrivileged: make(map[PermissionType][]string),
+							All:             make(map[PermissionType][]string),
+						}
+						out[key][method] = operation
+					}
+					for _, permissionType := range pathSet.SchemeKeys {
+						operation.All[permissionType] = append(operation.All[permissionType], name)
+						if least[permissionType] {
+							operation.LeastPrivileged[permissionType] = append(operation.LeastPrivileged[permissionType], name)
+						}
+					}
+				}
+			}
+		}
+	}
+	for _, operations := range out {
+		for _, operation := range operations {
+			for _, names := range operation.All {
+				sort.Strings(names)
+			}
+			for _, names := range operation.LeastPrivileged {
+				sort.Strings(names)
+			}
+		}
+	}
+	return out, nil
+}
+
+// leastPrivilegedPermissionTypes parses the flags like `least=DelegatedWork,Application`.
+func leastPrivilegedPermissionTypes(flags string) map[PermissionType]bool {
+	out := make(map[PermissionType]bool)
+	for _, flag := range strings.Split(flags, ";") {
+		value, ok := strings.CutPrefix(strings.TrimSpace(flag), "least=")
+		if !ok {
+			continue
+		}
+		for _, permissionType := range strings.Split(value, ",") {
+			out[PermissionType(strings.TrimSpace(permissionType))] = true
+		}
+	}
+	return out
+}
+
+func permissionPathKey(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	normalizedPath, _, _ := normalizeTemplatedPath(path)
+	return strings.ToLower(normalizedPath)
+}
+
+// operations returns the permissions of the operations defined in the path, sorted by method.
+func (p permissionIndex) operations(url string, item *pathIndex) []OperationPermissions {
+	if item == nil {
+		return nil
+	}
+	operations := p[permissionPathKey(url)]
+	out := make([]OperationPermissions, 0, len(operations))
+	for method, operation := range operations {
+		if item.operation(method) == nil {
+			continue
+		}
+		value := *operation
+		value.Url = url
+		out = append(out, value)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
+
+// resourcePermissions returns the permissions of the operations of the resource, and also the operations of the
+// entities for the collections.
+func (p permissionIndex) resourcePermissions(url string, kind ResourceKind, index *schemaIndex) []OperationPermissions {
+	if p == nil {
+		return nil
+	}
+	out := p.operations(url, index.find(url))
+	if kind == ResourceKindCollection {
+		normalizedUrl, _, _ := normalizeTemplatedPath(url)
+		if item := index.normalizedPaths[normalizedUrl+"/{}"]; item != nil {
+			out = append(out, p.operations(item.path, item)...)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,430 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RenderOptions controls how the types are rendered by Render and RenderType.
+type RenderOptions struct {
+	// MaxDepth is the depth of the nested properties which are rendered, the deeper properties are marked by `(...)`.
+	// It's unlimited if it's not specified, the depth is still bounded because the navigation properties are not
+	// expanded by default and the recursive types are only expanded once.
+	MaxDepth int
+
+	// DescriptionLength is the max length of the description snippets, the descriptions are omitted if it's negative.
+	// It's 60 if it's not specified.
+	DescriptionLength int
+
+	// ExpandNavigation expands the entity types of the navigation properties. They're not expanded by default, because
+	// they reach most of the entity types.
+	ExpandNavigation bool
+
+	// Details renders the constraints of the types, e.g. the patterns, the length limits and the default values, and
+	// also the base types and the deprecation details, e.g. for the golden files.
+	Details bool
+}
+
+// Render returns the resource definition as an indented tree, each line is a property with its type, flags, enum
+// values and a snippet of its description, e.g.
+//
+//	/applications (collection) microsoft.graph.application
+//	body: application
+//	├── displayName: string [required] - The display name for the application.
+//	└── web: webApplication
+//	    └── redirectUris: string[]
+//
+// The objects are rendered by their names. The recursive types are marked by `(recursive)` and are only expanded once,
+// the properties deeper than MaxDepth are marked by `(...)`, and the navigation properties are not expanded unless
+// ExpandNavigation is true.
+func Render(resource *ResourceType, options *RenderOptions) string {
+	if resource == nil {
+		return ""
+	}
+	builder := &strings.Builder{}
+	builder.WriteString(resource.Url)
+	if resource.Kind != "" {
+		fmt.Fprintf(builder, " (%s)", resource.Kind)
+	}
+	if resource.EntityType != "" {
+		fmt.Fprintf(builder, " %s", resource.EntityType)
+	}
+	if resource.Deprecated != nil {
+		builder.WriteString(" [deprecated]")
+	}
+	builder.WriteString("\n")
+	var body TypeBase
+	if resource.Body != nil {
+		body = resource.Body.Type
+	}
+	builder.WriteString(RenderType(body, options))
+	return builder.String()
+}
+
+// RenderType returns the type as an indented tree, see Render.
+func RenderType(t TypeBase, options *RenderOptions) string {
+	r := &treeRenderer{
+		builder: &strings.Builder{},
+		visited: make(map[*ObjectType]bool),
+	}
+	if options != nil {
+		r.options = *options
+	}
+	r.node("body", t, treeNodeInfo{}, "", "", 0)
+	return r.builder.String()
+}
+
+type treeRenderer struct {
+	options RenderOptions
+	builder *strings.Builder
+	// visited are the objects which are being expanded, they're the ancestors of the current node
+	visited map[*ObjectType]bool
+}
+
+// treeNodeInfo is the information of the property which is rendered after the type.
+type treeNodeInfo struct {
+	flags       []ObjectPropertyFlag
+	navigation  bool
+	deprecation *Deprecation
+	description string
+}
+
+// node writes the line of the type and its children, the connector is the prefix of the line and the prefix is the
+// prefix of the children.
+func (r *treeRenderer) node(label string, t TypeBase, info treeNodeInfo, connector string, prefix string, depth int) {
+	r.builder.WriteString(connector)
+	r.builder.WriteString(label)
+	r.builder.WriteString(": ")
+	r.builder.WriteString(typeSummary(t))
+	if r.options.Details {
+		for _, detail := range typeDetails(t) {
+			fmt.Fprintf(r.builder, " %s", detail)
+		}
+	}
+	if flags := renderTreeFlags(t, info.flags); len(flags) != 0 {
+		fmt.Fprintf(r.builder, " [%s]", strings.Join(flags, ", "))
+	}
+	if enum := r.enumValues(t); len(enum) != 0 {
+		fmt.Fprintf(r.builder, " enum(%s)", strings.Join(enum, " | "))
+	}
+	switch {
+	case info.deprecation != nil && r.options.Details:
+		fmt.Fprintf(r.builder, " deprecated(%s)", deprecationDetails(info.deprecation))
+	case info.deprecation != nil:
+		r.builder.WriteString(" (deprecated)")
+	}
+	if description := r.descriptionSnippet(info.description); description != "" {
+		fmt.Fprintf(r.builder, " - %s", description)
+	}
+
+	objectType := innerObjectType(t)
+	switch {
+	case objectType != nil && r.visited[objectType]:
+		r.builder.WriteString(" (recursive)\n")
+		return
+	case !hasTreeChildren(t), info.navigation && !r.options.ExpandNavigation:
+		r.builder.WriteString("\n")
+		return
+	case r.options.MaxDepth > 0 && depth >= r.options.MaxDepth:
+		r.builder.WriteString(" (...)\n")
+		return
+	}
+	r.builder.WriteString("\n")
+
+	if objectType != nil {
+		r.visited[objectType] = true
+		defer delete(r.visited, objectType)
+	}
+	r.children(t, prefix, depth+1)
+}
+
+func (r *treeRenderer) children(t TypeBase, prefix string, depth int) {
+	type child struct {
+		label string
+		t     TypeBase
+		info  treeNodeInfo
+	}
+	children := make([]child, 0)
+	switch v := t.(type) {
+	case *ArrayType:
+		if v.ItemType != nil {
+			r.children(v.ItemType.Type, prefix, depth)
+		}
+		return
+	case *UnionType:
+		for i, element := range v.Elements {
+			var elementType TypeBase
+			if element != nil {
+				elementType = element.Type
+			}
+			children = append(children, child{label: fmt.Sprintf("[%d]", i), t: elementType})
+		}
+	case *ObjectType:
+		names := make([]string, 0, len(v.Properties))
+		for name := range v.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property := v.Properties[name]
+			var propertyType TypeBase
+			if property.Type != nil {
+				propertyType = property.Type.Type
+			}
+			info := treeNodeInfo{
+				flags:       property.Flags,
+				navigation:  property.IsNavigation(),
+				deprecation: property.Deprecated,
+			}
+			if property.Description != nil {
+				info.description = *property.Description
+			}
+			children = append(children, child{label: name, t: propertyType, info: info})
+		}
+		if v.AdditionalProperties != nil {
+			children = append(children, child{label: "*", t: v.AdditionalProperties.Type})
+		}
+	}
+
+	for i, c := range children {
+		connector, childPrefix := "├── ", "│   "
+		if i == len(children)-1 {
+			connector, childPrefix = "└── ", "    "
+		}
+		r.node(c.label, c.t, c.info, prefix+connector, prefix+childPrefix, depth)
+	}
+}
+
+// enumValues returns the enum values of the type, the deprecated values are annotated with the deprecations in details.
+func (r *treeRenderer) enumValues(t TypeBase) []string {
+	values := enumValues(t)
+	if !r.options.Details {
+		return values
+	}
+	deprecations := enumDeprecations(t)
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if deprecation := deprecations[value]; deprecation != nil {
+			value = fmt.Sprintf("%s(deprecated: %s)", value, deprecationDetails(deprecation))
+		}
+		out = append(out, value)
+	}
+	return out
+}
+
+func (r *treeRenderer) descriptionSnippet(description string) string {
+	maxLength := r.options.DescriptionLength
+	if maxLength == 0 {
+		maxLength = 60
+	}
+	if maxLength < 0 {
+		return ""
+	}
+	description = strings.Join(strings.Fields(description), " ")
+	if runes := []rune(description); len(runes) > maxLength {
+		return strings.TrimSpace(string(runes[:maxLength])) + "..."
+	}
+	return description
+}
+
+// typeSummary returns the type in one line, e.g. `application`, `string(date-time)` and `string[]`.
+func typeSummary(t TypeBase) string {
+	switch v := t.(type) {
+	case *ObjectType:
+		if v.Name != "" {
+			return v.Name
+		}
+		return "object"
+	case *ArrayType:
+		if v.ItemType == nil || v.ItemType.Type == nil {
+			return "any[]"
+		}
+		if _, ok := v.ItemType.Type.(*UnionType); ok {
+			return "(" + typeSummary(v.ItemType.Type) + ")[]"
+		}
+		return typeSummary(v.ItemType.Type) + "[]"
+	case *UnionType:
+		elements := make([]string, 0, len(v.Elements))
+		for _, element := range v.Elements {
+			if element != nil {
+				elements = append(elements, typeSummary(element.Type))
+			}
+		}
+		return strings.Join(elements, " | ")
+	case *StringType:
+		if v.Format != "" {
+			return "string(" + v.Format + ")"
+		}
+		return "string"
+	case *NumberType:
+		if v.Format != "" {
+			return "number(" + v.Format + ")"
+		}
+		return "number"
+	case *BooleanType:
+		return "boolean"
+	case *AnyType:
+		return "any"
+	}
+	return "unknown"
+}
+
+// typeDetails returns the constraints of the type, e.g. `pattern="^[A-Z]{3}$"`, `default=1` and the base types.
+func typeDetails(t TypeBase) []string {
+	out := make([]string, 0)
+	switch v := t.(type) {
+	case *ObjectType:
+		if len(v.BaseTypes) != 0 {
+			out = append(out, "extends "+strings.Join(v.BaseTypes, ", "))
+		}
+	case *ArrayType:
+		out = append(out, lengthDetails(v.MinLength, v.MaxLength)...)
+		if v.ItemType != nil {
+			if items := typeDetails(v.ItemType.Type); len(items) != 0 {
+				out = append(out, "items("+strings.Join(items, " ")+")")
+			}
+		}
+	case *StringType:
+		out = append(out, lengthDetails(v.MinLength, v.MaxLength)...)
+		if v.Pattern != "" {
+			out = append(out, fmt.Sprintf("pattern=%q", v.Pattern))
+		}
+		if v.Default != nil {
+			out = append(out, fmt.Sprintf("default=%q", *v.Default))
+		}
+	case *NumberType:
+		if v.MinValue != nil {
+			out = append(out, fmt.Sprintf("min=%v", *v.MinValue))
+		}
+		if v.MaxValue != nil {
+			out = append(out, fmt.Sprintf("max=%v", *v.MaxValue))
+		}
+		if v.Default != nil {
+			out = append(out, fmt.Sprintf("default=%v", *v.Default))
+		}
+	case *BooleanType:
+		if v.Default != nil {
+			out = append(out, fmt.Sprintf("default=%v", *v.Default))
+		}
+	}
+	return out
+}
+
+func lengthDetails(min, max *uint64) []string {
+	out := make([]string, 0)
+	if min != nil {
+		out = append(out, fmt.Sprintf("minLength=%d", *min))
+	}
+	if max != nil {
+		out = append(out, fmt.Sprintf("maxLength=%d", *max))
+	}
+	return out
+}
+
+// deprecationDetails returns the deprecation in one line, e.g. `date="2024-01-01" description="Use name instead."`.
+func deprecationDetails(deprecation *Deprecation) string {
+	parts := make([]string, 0)
+	for _, part := range [][2]string{
+		{"date", deprecation.Date},
+		{"removalDate", deprecation.RemovalDate},
+		{"version", deprecation.Version},
+		{"description", deprecation.Description},
+	} {
+		if part[1] != "" {
+			parts = append(parts, fmt.Sprintf("%s=%q", part[0], part[1]))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func renderTreeFlags(t TypeBase, flags []ObjectPropertyFlag) []string {
+	names := []struct {
+		flag ObjectPropertyFlag
+		name string
+	}{
+		{Required, "required"},
+		{ReadOnly, "readonly"},
+		{WriteOnly, "writeonly"},
+		{CreateOnly, "createonly"},
+		{Identifier, "identifier"},
+		{Navigation, "navigation"},
+	}
+	var merged ObjectPropertyFlag
+	for _, flag := range flags {
+		merged |= flag
+	}
+	out := make([]string, 0)
+	for _, item := range names {
+		if merged&item.flag != 0 {
+			out = append(out, item.name)
+		}
+	}
+	if isSensitiveType(t) {
+		out = append(out, "sensitive")
+	}
+	return out
+}
+
+func isSensitiveType(t TypeBase) bool {
+	switch v := t.(type) {
+	case *StringType:
+		return v.Sensitive
+	case *ObjectType:
+		return v.Sensitive
+	case *ArrayType:
+		return v.ItemType != nil && isSensitiveType(v.ItemType.Type)
+	}
+	return false
+}
+
+// enumValues returns the enum values of the string type or the items of the array type.
+func enumValues(t TypeBase) []string {
+	switch v := t.(type) {
+	case *StringType:
+		return v.Enum
+	case *ArrayType:
+		if v.ItemType != nil {
+			return enumValues(v.ItemType.Type)
+		}
+	}
+	return nil
+}
+
+// enumDeprecations returns the deprecations of the enum values of the string type or the items of the array type.
+func enumDeprecations(t TypeBase) map[string]*Deprecation {
+	switch v := t.(type) {
+	case *StringType:
+		return v.DeprecatedEnum
+	case *ArrayType:
+		if v.ItemType != nil {
+			return enumDeprecations(v.ItemType.Type)
+		}
+	}
+	return nil
+}
+
+// innerObjectType returns the object type of the object or the items of the array, it returns nil otherwise.
+func innerObjectType(t TypeBase) *ObjectType {
+	switch v := t.(type) {
+	case *ObjectType:
+		return v
+	case *ArrayType:
+		if v.ItemType != nil {
+			return innerObjectType(v.ItemType.Type)
+		}
+	}
+	return nil
+}
+
+func hasTreeChildren(t TypeBase) bool {
+	switch v := t.(type) {
+	case *ObjectType:
+		return len(v.Properties) != 0 || v.AdditionalProperties != nil
+	case *ArrayType:
+		return v.ItemType != nil && hasTreeChildren(v.ItemType.Type)
+	case *UnionType:
+		return len(v.Elements) != 0
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package types
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func Test_Render(t *testing.T) {
+	loader := NewMSGraphSchemaLoader(os.DirFS("testdata"))
+	resource := loader.GetResourceDefinition("v1.0", "/widgets")
+	if resource == nil {
+		t.Fatalf("failed to load resource definition for %s", "/widgets")
+	}
+
+	out := Render(resource, nil)
+	for _, line := range []string{
+		"/widgets (collection) microsoft.graph.widget\n",
+		"body: widget\n",
+		"├── displayName: string [required] - The display name of the widget.\n",
+		"├── id: string [readonly, identifier] - The unique identifier for an entity. Read-only.\n",
+		"├── password: string(password) [createonly, sensitive]\n",
+		"│   ├── mode: string [required] enum(manual | automatic)\n",
+		"└── value: string | number(double) [createonly]\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("expect the tree to contain %q but got:\n%s", line, out)
+		}
+	}
+
+	if !strings.Contains(out, "├── owners: directoryObject[] [navigation]\n") || strings.Contains(out, "deletedDateTime") {
+		t.Errorf("expect the navigation properties not to be expanded but got:\n%s", out)
+	}
+	out = Render(resource, &RenderOptions{ExpandNavigation: true})
+	if !strings.Contains(out, "│   ├── deletedDateTime: string(date-time) [readonly]\n") {
+		t.Errorf("expect the navigation properties to be expanded but got:\n%s", out)
+	}
+
+	out = Render(resource, &RenderOptions{MaxDepth: 1, DescriptionLength: -1})
+	if !strings.Contains(out, "├── settings: widgetSettings (...)\n") || strings.Contains(out, "mode:") {
+		t.Errorf("expect the nested properties to be omitted but got:\n%s", out)
+	}
+	if strings.Contains(out, "The display name") {
+		t.Errorf("expect the descriptions to be omitted but got:\n%s", out)
+	}
+
+	node := &ObjectType{Type: "object", Name: "node", Properties: map[string]ObjectProperty{}}
+	node.Properties["children"] = ObjectProperty{Type: &TypeReference{Type: &ArrayType{Type: "array", ItemType: &TypeReference{Type: node}}}}
+	expected := "body: node\n└── children: node[] (recursive)\n"
+	if out := RenderType(node, nil); out != expected {
+		t.Errorf("expect %q but got %q", expected, out)
+	}
+}
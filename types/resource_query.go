@@ -0,0 +1,151 @@
+package types
+
+import (
+	"sort"
+	"strings"
+)
+
+type ResourceKind string
+
+const (
+	// ResourceKindCollection is a collection whose entities are created by POST, e.g. `/applications`.
+	ResourceKindCollection ResourceKind = "collection"
+
+	// ResourceKindReference is a collection of references, e.g. `/groups/{group-id}/members/$ref`.
+	ResourceKindReference ResourceKind = "reference"
+
+	// ResourceKindReadable is a path which can be read by GET, e.g. `/me`.
+	ResourceKindReadable ResourceKind = "readable"
+)
+
+// ResourceQuery filters the resources, the resources must match all specified conditions.
+type ResourceQuery struct {
+	// Kinds are the kinds of the resources, the resources listed by ListResources are matched if it's empty.
+	Kinds []ResourceKind
+	// UrlPrefix is the prefix of the URL, the variable names are ignored, e.g. `/users/{id}` matches `/users/{user-id}/...`.
+	UrlPrefix string
+	// EntityType is the name of the entity type, e.g. `microsoft.graph.application` or `application`.
+	EntityType string
+	// Tag is one of the tags of the operation, e.g. `applications.application`.
+	Tag string
+	// Text is searched in the name and the description case-insensitively.
+	Text string
+}
+
+// QueryResources returns the resources which match the query, sorted by name and then URL.
+func (r *MSGraphSchemaLoader) QueryResources(apiVersion string, query ResourceQuery) []ResourceType {
+	index := r.getSchemaIndex(apiVersion)
+	if index == nil {
+		return nil
+	}
+
+	kinds := query.Kinds
+	if len(kinds) == 0 {
+		kinds = []ResourceKind{ResourceKindCollection, ResourceKindReference}
+	}
+	candidates := index.resources
+	for _, kind := range kinds {
+		if kind == ResourceKindReadable {
+			candidates = append(append([]ResourceType(nil), index.resources...), index.readableResources...)
+			break
+		}
+	}
+
+	out := make([]ResourceType, 0)
+	for _, resource := range candidates {
+		if query.match(resource, kinds) {
+			out = append(out, resource.copy())
+		}
+	}
+	sortResources(out)
+	return out
+}
+
+// FindResource returns the resource addressed by the URL, the variable names in the URL are ignored.
+// It returns the collection or the reference if the URL can be created by POST, otherwise the readable resource.
+func (r *MSGraphSchemaLoader) FindResource(apiVersion, url string) *ResourceType {
+	index := r.getSchemaIndex(apiVersion)
+	if index == nil {
+		return nil
+	}
+	item := index.find(url)
+	if item == nil {
+		return nil
+	}
+	var out ResourceType
+	switch {
+	case item.post != nil && strings.Contains(item.path, "/$ref"):
+		out = index.newResourceType(item.path, ResourceKindReference, item.post)
+	case item.post != nil:
+		out = index.newResourceType(item.path, ResourceKindCollection, item.post)
+	case item.get != nil:
+		out = index.newResourceType(item.path, ResourceKindReadable, item.get)
+	default:
+		return nil
+	}
+	return &out
+}
+
+func (q ResourceQuery) match(resource ResourceType, kinds []ResourceKind) bool {
+	kindMatched := false
+	for _, kind := range kinds {
+		if resource.Kind == kind {
+			kindMatched = true
+			break
+		}
+	}
+	if !kindMatched {
+		return false
+	}
+
+	if q.UrlPrefix != "" {
+		prefix := q.UrlPrefix
+		if !strings.HasPrefix(prefix, "/") {
+			prefix = "/" + prefix
+		}
+		normalizedPrefix, _, _ := normalizeTemplatedPath(prefix)
+		normalizedUrl, _, _ := normalizeTemplatedPath(resource.Url)
+		if !strings.HasPrefix(resource.Url, prefix) && !strings.HasPrefix(normalizedUrl, normalizedPrefix) {
+			return false
+		}
+	}
+
+	if q.EntityType != "" && !strings.EqualFold(resource.EntityType, q.EntityType) &&
+		!strings.HasSuffix(strings.ToLower(resource.EntityType), "."+strings.ToLower(q.EntityType)) {
+		return false
+	}
+
+	if q.Tag != "" {
+		tagMatched := false
+		for _, tag := range resource.Tags {
+			if tag == q.Tag {
+				tagMatched = true
+				break
+			}
+		}
+		if !tagMatched {
+			return false
+		}
+	}
+
+	if q.Text != "" {
+		text := strings.ToLower(q.Text)
+		if !strings.Contains(strings.ToLower(resource.Name), text) && !strings.Contains(strings.ToLower(resource.Description), text) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortResources sorts the resources by name, the resources with the same or empty names are sorted by URL.
+func sortResources(resources []ResourceType) {
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].Name != resources[j].Name {
+			return resources[i].Name < resources[j].Name
+		}
+		if resources[i].Url != resources[j].Url {
+			return resources[i].Url < resources[j].Url
+		}
+		return resources[i].Kind < resources[j].Kind
+	})
+}
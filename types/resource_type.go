@@ -8,15 +8,25 @@ import (
 var _ TypeBase = &ResourceType{}
 
 type ResourceType struct {
-	Type               string
-	Name               string
-	Url                string
-	Description        string
-	ExternalDocs       *ExternalDocumentation
+	Type         string
+	Kind         ResourceKind
+	Name         string
+	Url          string
+	Description  string
+	ExternalDocs *ExternalDocumentation
+	Tags         []string
+	// EntityType is the fully-qualified name of the entity type, e.g. `microsoft.graph.application`.
+	EntityType         string
 	ScopeTypes         []ScopeType
 	ReadOnlyScopeTypes []ScopeType
 	Body               *TypeReference
 	Flags              []ResourceTypeFlag
+	Deprecated         *Deprecation
+	// Permissions are the permissions of the operations of the resource, and also the operations of the entities
+	// for the collections, it's nil if the permissions are not available.
+	Permissions []OperationPermissions
+	// Resolver resolves the URLs in `@odata.bind` annotations, they're not validated if it's nil.
+	Resolver ReferenceResolver
 }
 
 type ExternalDocumentation struct {
@@ -24,15 +34,63 @@ type ExternalDocumentation struct {
 	Url         string `json:"url"`
 }
 
+// copy returns a copy of the resource whose slices and maps are not shared with the resource, the body and the
+// resolver are shared because they're not modified.
+func (t ResourceType) copy() ResourceType {
+	out := t
+	out.Tags = append([]string(nil), t.Tags...)
+	out.ScopeTypes = append([]ScopeType(nil), t.ScopeTypes...)
+	out.ReadOnlyScopeTypes = append([]ScopeType(nil), t.ReadOnlyScopeTypes...)
+	out.Flags = append([]ResourceTypeFlag(nil), t.Flags...)
+	if t.Permissions != nil {
+		out.Permissions = make([]OperationPermissions, 0, len(t.Permissions))
+		for _, operation := range t.Permissions {
+			operation.LeastPrivileged = copyPermissionMap(operation.LeastPrivileged)
+			operation.All = copyPermissionMap(operation.All)
+			out.Permissions = append(out.Permissions, operation)
+		}
+	}
+	return out
+}
+
+// Validate validates the body and returns the errors, see Diagnose.
 func (t *ResourceType) Validate(body interface{}, path string) []error {
+	return t.Diagnose(body, path, nil).Errors()
+}
+
+// Diagnose validates the body and reports the errors, and also the warnings like the usages of deprecated elements.
+// The URLs in the `@odata.bind` annotations are resolved by the Resolver, they're only checked by their shapes if
+// the Resolver is nil.
+func (t *ResourceType) Diagnose(body interface{}, path string, options *ValidationOptions) Diagnostics {
+	if t == nil || body == nil || IsUnknown(body) {
+		return Diagnostics{}
+	}
+	diagnostics := make(Diagnostics, 0)
+	if t.Deprecated != nil {
+		diagnostics = append(diagnostics, deprecationDiagnostic(path, t.Deprecated))
+	}
+	if t.Body != nil && t.Body.Type != nil {
+		diagnostics = append(diagnostics, Diagnose(t.Body.Type, body, path, options)...)
+		diagnostics = append(diagnostics, errorDiagnostics(path, validateODataBindReferences(t.Body.Type, body, path, t.Resolver)...)...)
+	}
+	return diagnostics
+}
+
+func (t *ResourceType) ApplyDefaults(body interface{}) interface{} {
 	if t == nil || body == nil {
-		return []error{}
+		return body
 	}
-	errors := make([]error, 0)
 	if t.Body != nil && t.Body.Type != nil {
-		errors = append(errors, t.Body.Type.Validate(body, path)...)
+		return ApplyDefaults(t.Body.Type, body)
 	}
-	return errors
+	return body
+}
+
+// ValidateWithWarnings validates the body and also returns the warnings of the deprecated resource, properties and enum values
+// used by the body, so that they can be migrated before they're removed. It's a shortcut of Diagnose.
+func (t *ResourceType) ValidateWithWarnings(body interface{}, path string) ([]error, []*DeprecationWarning) {
+	diagnostics := t.Diagnose(body, path, nil)
+	return diagnostics.Errors(), diagnostics.Deprecations()
 }
 
 func (t *ResourceType) FilterReadOnlyFields(i interface{}) interface{} {
@@ -55,6 +113,49 @@ func (t *ResourceType) FilterConfigurableFields(body interface{}) interface{} {
 	return body
 }
 
+func (t *ResourceType) FilterWriteOnlyFields(body interface{}) interface{} {
+	if t == nil || body == nil {
+		return body
+	}
+	if t.Body != nil && t.Body.Type != nil {
+		return FilterWriteOnlyFields(t.Body.Type, body)
+	}
+	return body
+}
+
+func (t *ResourceType) MergeWriteOnlyFields(state interface{}, config interface{}) interface{} {
+	if t == nil || state == nil {
+		return state
+	}
+	if t.Body != nil && t.Body.Type != nil {
+		return MergeWriteOnlyFields(t.Body.Type, state, config)
+	}
+	return state
+}
+
+func (t *ResourceType) Redact(body interface{}) interface{} {
+	if t == nil || body == nil {
+		return body
+	}
+	if t.Body != nil && t.Body.Type != nil {
+		return Redact(t.Body.Type, body)
+	}
+	return body
+}
+
+// Sample returns a sample request body which passes Validate, the entity URLs are generated by the resolver.
+func (t *ResourceType) Sample(options *SampleOptions) interface{} {
+	if t == nil || t.Body == nil || t.Body.Type == nil {
+		return nil
+	}
+	if references, ok := t.Resolver.(referenceSampler); ok {
+		out := *options.orDefault()
+		out.references = references
+		options = &out
+	}
+	return Sample(t.Body.Type, options)
+}
+
 func (t *ResourceType) AsTypeBase() *TypeBase {
 	typeBase := TypeBase(t)
 	return &typeBase
@@ -0,0 +1,288 @@
+package types
+
+import (
+	"math/rand"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+type SampleMode int
+
+const (
+	// SampleMinimal generates the required properties only.
+	SampleMinimal SampleMode = iota
+
+	// SampleFull generates all writable properties which are not deprecated, and binds the navigation properties to
+	// entities by `@odata.bind` if the entity URLs can be generated.
+	SampleFull
+)
+
+// SampleOptions controls how the sample values are generated.
+type SampleOptions struct {
+	Mode SampleMode
+
+	// Rand generates random values when it's specified, e.g. rand.New(rand.NewSource(seed)) for the property-based
+	// tests, otherwise the generated values are deterministic.
+	Rand *rand.Rand
+
+	// MaxDepth is the depth of the nested objects which are generated in the full mode, the deeper objects only have
+	// the required properties, and the objects nested deeper than it are empty, so that the recursive types terminate.
+	// It's 8 if it's not specified.
+	MaxDepth int
+
+	depth int
+	// references generates the entity URLs, it's set by ResourceType.Sample from its resolver.
+	references referenceSampler
+}
+
+// Sample returns a sample value of the type which passes Validate, the options could be nil.
+func Sample(t TypeBase, options *SampleOptions) interface{} {
+	if v, ok := t.(interface {
+		Sample(*SampleOptions) interface{}
+	}); ok {
+		return v.Sample(options)
+	}
+	return nil
+}
+
+func (o *SampleOptions) orDefault() *SampleOptions {
+	if o == nil {
+		return &SampleOptions{}
+	}
+	return o
+}
+
+// child returns the options of the nested values.
+func (o *SampleOptions) child() *SampleOptions {
+	out := *o.orDefault()
+	out.depth++
+	return &out
+}
+
+// isFull returns true if the optional properties should be generated at the current depth.
+func (o *SampleOptions) isFull() bool {
+	return o.Mode == SampleFull && o.depth < o.maxDepth()
+}
+
+// isTooDeep returns true if the nested objects shouldn't be generated at the current depth, even the required ones.
+func (o *SampleOptions) isTooDeep() bool {
+	return o.depth > o.maxDepth()
+}
+
+func (o *SampleOptions) maxDepth() int {
+	if o.MaxDepth <= 0 {
+		return 8
+	}
+	return o.MaxDepth
+}
+
+// sampleReference returns the URL of an entity which is assignable to the target type, the target could be nil for
+// any entity. It returns false if the URL can't be generated.
+func (o *SampleOptions) sampleReference(target TypeBase) (string, bool) {
+	if o.references != nil {
+		return o.references.sampleReference(target, o)
+	}
+	if target == nil {
+		return "https://graph.microsoft.com/v1.0/directoryObjects/" + o.sampleUUID(), true
+	}
+	return "", false
+}
+
+// sampleUUID returns a random UUID, it returns a fixed UUID if the values are deterministic.
+func (o *SampleOptions) sampleUUID() string {
+	if o.Rand == nil {
+		return formatSamples["uuid"]
+	}
+	return sampleUUID(o.Rand)
+}
+
+// intn returns a random number in [0, n), it returns 0 if the values are deterministic.
+func (o *SampleOptions) intn(n int) int {
+	if o.Rand == nil || n <= 0 {
+		return 0
+	}
+	return o.Rand.Intn(n)
+}
+
+// formatSamples are the sample values of the string formats.
+var formatSamples = map[string]string{
+	"date-time": "2024-01-01T00:00:00Z",
+	"date":      "2024-01-01",
+	"time":      "00:00:00",
+	"duration":  "PT1H",
+	"uuid":      "00000000-0000-0000-0000-000000000000",
+	"email":     "user@example.com",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "127.0.0.1",
+	"ipv6":      "::1",
+	"base64url": "c2FtcGxl",
+	"byte":      "c2FtcGxl",
+	"binary":    "c2FtcGxl",
+}
+
+// sampleString returns a string which matches the pattern and the length limits, it returns false if it fails.
+func sampleString(pattern string, minLength, maxLength *uint64, format string, options *SampleOptions) (string, bool) {
+	var re *regexp.Regexp
+	if pattern != "" {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			re = nil
+		}
+	}
+	isValid := func(value string) bool {
+		if minLength != nil && uint64(len(value)) < *minLength {
+			return false
+		}
+		if maxLength != nil && uint64(len(value)) > *maxLength {
+			return false
+		}
+		return re == nil || re.MatchString(value)
+	}
+
+	candidates := make([]string, 0)
+	if value, ok := formatSamples[format]; ok {
+		if format == "uuid" {
+			value = options.sampleUUID()
+		}
+		candidates = append(candidates, value)
+	}
+	if re != nil {
+		for i := 0; i < 10; i++ {
+			if value, ok := samplePattern(pattern, options); ok {
+				candidates = append(candidates, value)
+			}
+			if options.Rand == nil {
+				break
+			}
+		}
+	} else {
+		value := "sample"
+		if options.Rand != nil {
+			value = randomString(options.Rand, 1+options.Rand.Intn(16))
+		}
+		candidates = append(candidates, value)
+	}
+
+	for _, value := range candidates {
+		if minLength != nil && uint64(len(value)) < *minLength && re == nil {
+			value += strings.Repeat("a", int(*minLength)-len(value))
+		}
+		if maxLength != nil && uint64(len(value)) > *maxLength && re == nil {
+			value = value[:*maxLength]
+		}
+		if isValid(value) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// samplePattern returns a string which matches the pattern, the pattern is parsed by regexp/syntax and each node
+// generates the shortest match unless the values are random.
+func samplePattern(pattern string, options *SampleOptions) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	builder := strings.Builder{}
+	generateRegexp(&builder, re.Simplify(), options)
+	value := builder.String()
+	matched, err := regexp.MatchString(pattern, value)
+	return value, err == nil && matched
+}
+
+func generateRegexp(builder *strings.Builder, re *syntax.Regexp, options *SampleOptions) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		builder.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		if len(re.Rune) < 2 {
+			return
+		}
+		// the ranges are pairs of the low and the high runes, they're clipped to the printable ASCII runes, so that the
+		// random values don't contain the control characters
+		ranges := make([][2]rune, 0)
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			if low, high := max(re.Rune[i], ' '), min(re.Rune[i+1], '~'); low <= high {
+				ranges = append(ranges, [2]rune{low, high})
+			}
+		}
+		if len(ranges) == 0 {
+			ranges = append(ranges, [2]rune{printableRune(re.Rune), printableRune(re.Rune)})
+		}
+		r := ranges[0]
+		if options.Rand != nil {
+			r = ranges[options.Rand.Intn(len(ranges))]
+		}
+		value := r[0]
+		if span := int(r[1] - r[0]); span > 0 && options.Rand != nil {
+			value += rune(options.Rand.Intn(span + 1))
+		}
+		builder.WriteRune(value)
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		builder.WriteRune('a')
+	case syntax.OpCapture:
+		generateRegexp(builder, re.Sub[0], options)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, -1
+		case syntax.OpPlus:
+			min, max = 1, -1
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 {
+			max = min + 3
+		}
+		count := min + options.intn(max-min+1)
+		for i := 0; i < count; i++ {
+			generateRegexp(builder, re.Sub[0], options)
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			generateRegexp(builder, sub, options)
+		}
+	case syntax.OpAlternate:
+		generateRegexp(builder, re.Sub[options.intn(len(re.Sub))], options)
+	}
+}
+
+// printableRune returns the first printable rune of the ranges of a character class, it returns the first rune if
+// none of them is printable.
+func printableRune(ranges []rune) rune {
+	for i := 0; i+1 < len(ranges); i += 2 {
+		// the runes are bounded, so that the huge ranges don't take long
+		for r := ranges[i]; r <= ranges[i+1] && r < ranges[i]+256; r++ {
+			if unicode.IsPrint(r) {
+				return r
+			}
+		}
+	}
+	return ranges[0]
+}
+
+func randomString(r *rand.Rand, length int) string {
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	b := make([]byte, length)
+	for i := range b {
+		b[i] = letters[r.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+func sampleUUID(r *rand.Rand) string {
+	const hex = "0123456789abcdef"
+	b := []byte("00000000-0000-4000-8000-000000000000")
+	for i := range b {
+		if b[i] == '0' {
+			b[i] = hex[r.Intn(len(hex))]
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,276 @@
+package types
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// schemaIndex contains the parts of the OpenAPI document which are used by the loader, it's much smaller than the
+// document because the unused components, parameters, responses and non-json contents are not retained.
+type schemaIndex struct {
+	// paths are keyed by the paths defined in the document
+	paths map[string]*pathIndex
+	// normalizedPaths are keyed by the paths whose variables are removed, e.g. `/users/{}`
+	normalizedPaths map[string]*pathIndex
+	// normalizedPrefixes are the prefixes of the normalized paths, e.g. `/users` and `/users/{}` of `/users/{}/manager`
+	normalizedPrefixes map[string]bool
+	// entitySets are the top level collections whose entities can be read, sorted, e.g. `/directoryObjects`
+	entitySets []string
+	// alternateKeys are the names of the alternate keys keyed by the normalized collection paths, e.g. `appId` of
+	// `/applications(appId='{appId}')`
+	alternateKeys map[string][]string
+	// resources are the resources listed by ListResources, sorted by name
+	resources []ResourceType
+	// readableResources are the resources listed by ListReadableResources, sorted by name
+	readableResources []ResourceType
+	// schemas are the schemas of the type definitions, keyed by the fully-qualified names, the other component schemas
+	// are only used to build the index. They're kept even if the document is dropped, because GetTypeDefinition
+	// converts them.
+	schemas map[string]*openapi3.SchemaRef
+	// typeDefinitions are the entity types and the complex types, sorted by name
+	typeDefinitions []TypeDefinition
+	// derivedTypes are the names of the types which are derived from the type directly or indirectly
+	derivedTypes map[string][]string
+	// permissions are the permissions of the operations, it's nil if the permissions are not available
+	permissions permissionIndex
+}
+
+type pathIndex struct {
+	path           string
+	normalizedPath string
+	get            *operationIndex
+	post           *operationIndex
+	patch          *operationIndex
+	delete         *operationIndex
+}
+
+type operationIndex struct {
+	summary      string
+	description  string
+	tags         []string
+	deprecated   *Deprecation
+	externalDocs *ExternalDocumentation
+	// requestBody is the schema of the json request body
+	requestBody *openapi3.Schema
+	// response is the schema of the json response of the 2XX status
+	response *openapi3.Schema
+	// entityType is the fully-qualified name of the entity type of the request body or the response
+	entityType string
+	// collection is true if the response is a collection of the entity type
+	collection bool
+}
+
+func newSchemaIndex(doc *openapi3.T, permissions permissionIndex) *schemaIndex {
+	out := &schemaIndex{
+		paths:              make(map[string]*pathIndex),
+		normalizedPaths:    make(map[string]*pathIndex),
+		normalizedPrefixes: make(map[string]bool),
+		alternateKeys:      make(map[string][]string),
+		schemas:            make(map[string]*openapi3.SchemaRef),
+		derivedTypes:       make(map[string][]string),
+		permissions:        permissions,
+	}
+	if doc == nil {
+		return out
+	}
+
+	if doc.Components != nil {
+		for name, schema := range doc.Components.Schemas {
+			out.schemas[name] = schema
+		}
+		for name := range out.schemas {
+			if kind := out.typeKind(name); kind != "" {
+				baseTypes := baseTypeNames(out.schemas[name].Value, map[*openapi3.Schema]bool{})
+				definition := TypeDefinition{
+					Name:        name,
+					Kind:        kind,
+					Description: out.schemas[name].Value.Description,
+				}
+				if len(baseTypes) != 0 {
+					definition.BaseType = baseTypes[0]
+				}
+				for _, baseType := range baseTypes {
+					out.derivedTypes[baseType] = append(out.derivedTypes[baseType], name)
+				}
+				out.typeDefinitions = append(out.typeDefinitions, definition)
+			}
+		}
+		sort.Slice(out.typeDefinitions, func(i, j int) bool {
+			return out.typeDefinitions[i].Name < out.typeDefinitions[j].Name
+		})
+		schemas := make(map[string]*openapi3.SchemaRef, len(out.typeDefinitions))
+		for _, definition := range out.typeDefinitions {
+			schemas[definition.Name] = out.schemas[definition.Name]
+		}
+		out.schemas = schemas
+	}
+
+	if doc.Paths == nil {
+		return out
+	}
+
+	// the paths are sorted, so that the same path wins when multiple paths have the same normalized path
+	pathItems := doc.Paths.Map()
+	paths := make([]string, 0, len(pathItems))
+	for path := range pathItems {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		pathItem := pathItems[path]
+		if pathItem == nil {
+			continue
+		}
+		normalizedPath, _, _ := normalizeTemplatedPath(path)
+		item := &pathIndex{
+			path:           path,
+			normalizedPath: normalizedPath,
+			get:            newOperationIndex(pathItem.Get),
+			post:           newOperationIndex(pathItem.Post),
+			patch:          newOperationIndex(pathItem.Patch),
+			delete:         newOperationIndex(pathItem.Delete),
+		}
+		out.paths[path] = item
+		out.normalizedPaths[normalizedPath] = item
+		if start := strings.LastIndex(normalizedPath, "("); start > strings.LastIndex(normalizedPath, "/") && strings.HasSuffix(normalizedPath, ")") {
+			for _, match := range alternateKeyRegex.FindAllStringSubmatch(normalizedPath[start:], -1) {
+				out.alternateKeys[normalizedPath[:start]] = append(out.alternateKeys[normalizedPath[:start]], match[1])
+			}
+		}
+		for i := 1; i < len(normalizedPath); i++ {
+			if normalizedPath[i] == '/' {
+				out.normalizedPrefixes[normalizedPath[:i]] = true
+			}
+		}
+	}
+
+	for path, item := range out.normalizedPaths {
+		if entitySetItemPathRegex.MatchString(path) && item.get != nil && item.get.response != nil {
+			out.entitySets = append(out.entitySets, strings.TrimSuffix(path, "/{}"))
+		}
+	}
+	sort.Strings(out.entitySets)
+
+	for path, item := range out.normalizedPaths {
+		if item.post == nil {
+			continue
+		}
+		kind := ResourceKindReference
+		if !strings.Contains(path, "/$ref") {
+			kind = ResourceKindCollection
+			itemPathItem := out.normalizedPaths[path+"/{}"]
+			if itemPathItem == nil || itemPathItem.get == nil || itemPathItem.delete == nil {
+				continue
+			}
+		}
+		out.resources = append(out.resources, out.newResourceType(item.path, kind, item.post))
+	}
+	sortResources(out.resources)
+
+	for path, item := range out.paths {
+		if item.get != nil {
+			out.readableResources = append(out.readableResources, out.newResourceType(path, ResourceKindReadable, item.get))
+		}
+	}
+	sortResources(out.readableResources)
+	return out
+}
+
+var alternateKeyRegex = regexp.MustCompile(`(\w+)='?\{[^}]*\}'?`)
+
+// entitySetItemPathRegex matches the normalized item paths of the top level collections, e.g. `/directoryObjects/{}`.
+var entitySetItemPathRegex = regexp.MustCompile(`^/[^/{}()]+/\{\}$`)
+
+func newOperationIndex(input *openapi3.Operation) *operationIndex {
+	if input == nil {
+		return nil
+	}
+	out := &operationIndex{
+		summary:     input.Summary,
+		description: input.Description,
+		tags:        input.Tags,
+		deprecated:  newDeprecation(input.Deprecated, input.Extensions),
+	}
+	if input.ExternalDocs != nil {
+		out.externalDocs = &ExternalDocumentation{
+			Description: input.ExternalDocs.Description,
+			Url:         input.ExternalDocs.URL,
+		}
+	}
+	if input.RequestBody != nil && input.RequestBody.Value != nil && input.RequestBody.Value.Content != nil {
+		if content := input.RequestBody.Value.Content.Get("application/json"); content != nil && content.Schema != nil {
+			out.requestBody = content.Schema.Value
+			out.entityType = schemaName(content.Schema)
+		}
+	}
+	if input.Responses != nil {
+		if response := input.Responses.Status(200); response != nil && response.Value != nil {
+			if content := response.Value.Content.Get("application/json"); content != nil && content.Schema != nil {
+				out.response = content.Schema.Value
+				if out.entityType == "" {
+					out.entityType, out.collection = entityTypeName(content.Schema)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// schemaName returns the fully-qualified name of the referenced schema, e.g. `microsoft.graph.application`.
+func schemaName(input *openapi3.SchemaRef) string {
+	if input == nil {
+		return ""
+	}
+	return strings.TrimPrefix(input.Ref, "#/components/schemas/")
+}
+
+// entityTypeName returns the name of the entity type of the response, it's the type of the items for the collection
+// responses, e.g. `microsoft.graph.application` of `microsoft.graph.applicationCollectionResponse`, and whether the
+// response is a collection.
+func entityTypeName(input *openapi3.SchemaRef) (string, bool) {
+	if input == nil || input.Value == nil {
+		return "", false
+	}
+	members := append([]*openapi3.SchemaRef{input}, input.Value.AllOf...)
+	for _, member := range members {
+		if member == nil || member.Value == nil {
+			continue
+		}
+		if value := member.Value.Properties["value"]; value != nil && value.Value != nil && value.Value.Items != nil {
+			if name := schemaName(value.Value.Items); name != "" {
+				return name, true
+			}
+		}
+	}
+	return schemaName(input), false
+}
+
+// find returns the path which matches the url, the url could be the path defined in the document or a path with
+// different variable names.
+func (s *schemaIndex) find(url string) *pathIndex {
+	if !strings.HasPrefix(url, "/") {
+		url = "/" + url
+	}
+	if item := s.paths[url]; item != nil {
+		return item
+	}
+	normalizedUrl, _, _ := normalizeTemplatedPath(url)
+	return s.normalizedPaths[normalizedUrl]
+}
+
+func (p *pathIndex) operation(method string) *operationIndex {
+	switch method {
+	case "GET":
+		return p.get
+	case "POST":
+		return p.post
+	case "PATCH":
+		return p.patch
+	case "DELETE":
+		return p.delete
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package types
+
+import (
+	"regexp"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// RedactedValue replaces the sensitive values in the redacted bodies.
+const RedactedValue = "***"
+
+// SensitivePropertyRule marks the properties whose names match the pattern as sensitive or not.
+type SensitivePropertyRule struct {
+	Pattern   *regexp.Regexp
+	Sensitive bool
+}
+
+// DefaultSensitivePropertyRules returns the rules used by the loaders unless WithSensitivePropertyRules is specified.
+// The rules are evaluated in order against the property names and the first matched rule wins, they're used when
+// the schema doesn't specify whether the property is sensitive.
+func DefaultSensitivePropertyRules() []SensitivePropertyRule {
+	return append([]SensitivePropertyRule(nil), defaultSensitivePropertyRules...)
+}
+
+var defaultSensitivePropertyRules = []SensitivePropertyRule{
+	// the metadata of secrets, e.g. `keyId`, `hint`, `passwordPolicies`, `forceChangePasswordNextSignIn`
+	{Pattern: regexp.MustCompile(`^(has|is|force|allow|enable|require)[A-Z]`), Sensitive: false},
+	{Pattern: regexp.MustCompile(`(?i)(id|ids|identifier|hint|type|types|usage|policy|policies|datetime|count|length|name|names|url|urls|uri|uris|thumbprint|version|enabled|required)$`), Sensitive: false},
+	// the containers of secrets, e.g. `passwordCredentials`, `passwordProfile`, the secrets inside are detected by their own names
+	{Pattern: regexp.MustCompile(`(?i)(credential|credentials|profile|profiles|settings|configuration|configurations)$`), Sensitive: false},
+	{Pattern: regexp.MustCompile(`(?i)(password|secret|token|passphrase|privatekey|connectionstring|apikey)`), Sensitive: true},
+	{Pattern: regexp.MustCompile(`^(key|pin)$`), Sensitive: true},
+}
+
+// isSensitiveSchema returns true if the schema is annotated as sensitive.
+func isSensitiveSchema(input *openapi3.Schema) bool {
+	sensitive, _ := schemaSensitivity(input)
+	return sensitive
+}
+
+// schemaSensitivity returns whether the schema is sensitive and whether it's annotated, the `x-ms-secret` extension
+// wins over the write-only and password annotations.
+func schemaSensitivity(input *openapi3.Schema) (sensitive bool, annotated bool) {
+	if input == nil {
+		return false, false
+	}
+	if secret, ok := input.Extensions["x-ms-secret"].(bool); ok {
+		return secret, true
+	}
+	if input.WriteOnly || input.Format == "password" {
+		return true, true
+	}
+	return false, false
+}
+
+// isSensitiveProperty returns true if the property is annotated as sensitive or its name matches a sensitive rule.
+func isSensitiveProperty(name string, input *openapi3.Schema, rules []SensitivePropertyRule) bool {
+	if sensitive, annotated := schemaSensitivity(input); annotated {
+		return sensitive
+	}
+	for _, rule := range rules {
+		if rule.Pattern != nil && rule.Pattern.MatchString(name) {
+			return rule.Sensitive
+		}
+	}
+	return false
+}
+
+// redactStrings returns a copy of the value of a sensitive object whose string values are replaced by RedactedValue,
+// the other values and the instance annotations are kept, so that the JSON types of the values don't change.
+func redactStrings(body interface{}) interface{} {
+	switch v := body.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{})
+		for key, value := range v {
+			if IsInstanceAnnotation(key) {
+				res[key] = value
+				continue
+			}
+			res[key] = redactStrings(value)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, 0, len(v))
+		for _, value := range v {
+			res = append(res, redactStrings(value))
+		}
+		return res
+	case string:
+		return RedactedValue
+	}
+	return body
+}
+
+// sensitiveMarker marks the sensitive properties when the conversion finishes, because the types of the recursive
+// objects are incomplete until then, e.g. their properties are populated after the properties referencing them.
+type sensitiveMarker struct {
+	rules []SensitivePropertyRule
+	// pending are the types of the sensitive properties which are not marked yet
+	pending []*TypeReference
+}
+
+// markProperty marks the type of the property as sensitive when the conversion finishes, if the property is sensitive.
+func (m *sensitiveMarker) markProperty(name string, input *openapi3.Schema, ref *TypeReference) {
+	if isSensitiveProperty(name, input, m.rules) {
+		m.pending = append(m.pending, ref)
+	}
+}
+
+// finish marks the types of the pending sensitive properties.
+func (m *sensitiveMarker) finish() {
+	for _, ref := range m.pending {
+		ref.Type = markSensitive(ref.Type)
+	}
+	m.pending = nil
+}
+
+// markSensitive returns a copy of the type which is marked as sensitive, the input type isn't modified because
+// the converted types are shared by all properties referencing the same schema.
+func markSensitive(input TypeBase) TypeBase {
+	switch t := input.(type) {
+	case *StringType:
+		out := *t
+		out.Sensitive = true
+		return &out
+	case *ObjectType:
+		out := *t
+		out.Sensitive = true
+		return &out
+	case *ArrayType:
+		if t.ItemType == nil || t.ItemType.Type == nil {
+			return input
+		}
+		out := *t
+		out.ItemType = &TypeReference{
+			Type: markSensitive(t.ItemType.Type),
+		}
+		return &out
+	case *UnionType:
+		out := *t
+		out.Elements = make([]*TypeReference, 0)
+		for _, element := range t.Elements {
+			if element.Type == nil {
+				out.Elements = append(out.Elements, element)
+				continue
+			}
+			out.Elements = append(out.Elements, &TypeReference{
+				Type: markSensitive(element.Type),
+			})
+		}
+		return &out
+	}
+	return input
+}
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// renderResource prints the resource definition in a stable text form, the body is rendered by RenderType with the
+// details of the types, and the navigation properties are expanded.
+func renderResource(resource *ResourceType) string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "url: %s\n", resource.Url)
+	fmt.Fprintf(builder, "name: %s\n", resource.Name)
+	fmt.Fprintf(builder, "kind: %s\n", resource.Kind)
+	if resource.EntityType != "" {
+		fmt.Fprintf(builder, "entityType: %s\n", resource.EntityType)
+	}
+	if len(resource.Tags) != 0 {
+		fmt.Fprintf(builder, "tags: %s\n", strings.Join(resource.Tags, ", "))
+	}
+	if resource.Deprecated != nil {
+		fmt.Fprintf(builder, "deprecated: %s\n", deprecationDetails(resource.Deprecated))
+	}
+	for _, flag := range resource.Flags {
+		if flag == ResourceTypeFlagReadOnly {
+			builder.WriteString("readOnly: true\n")
+		}
+	}
+	if resource.Body == nil {
+		builder.WriteString("body: <none>\n")
+		return builder.String()
+	}
+	builder.WriteString(RenderType(resource.Body.Type, &RenderOptions{
+		DescriptionLength: -1,
+		ExpandNavigation:  true,
+		Details:           true,
+	}))
+	return builder.String()
+}
+
+var snapshotFileNameRegex = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// snapshotFileName returns the name of the golden file of the url, e.g. `widgets_widget-id.txt`.
+func snapshotFileName(url string) string {
+	name := strings.Trim(snapshotFileNameRegex.ReplaceAllString(strings.ReplaceAll(url, "/", "_"), ""), "_")
+	if name == "" {
+		name = "root"
+	}
+	return name + ".txt"
+}
+
+func Test_Snapshots(t *testing.T) {
+	loader := NewMSGraphSchemaLoader(os.DirFS("testdata"))
+	apiVersion := "v1.0"
+	dir := filepath.Join("testdata", "snapshots", apiVersion)
+
+	expected := make(map[string]bool)
+	for _, resource := range loader.ListResources(apiVersion) {
+		definition := loader.GetResourceDefinition(apiVersion, resource.Url)
+		if definition == nil {
+			t.Errorf("expect the definition of %s but got nil", resource.Url)
+			continue
+		}
+		name := snapshotFileName(resource.Url)
+		if expected[name] {
+			t.Errorf("expect the golden file of %s to be unique: %s", resource.Url, name)
+			continue
+		}
+		expected[name] = true
+
+		actual := renderResource(definition)
+		filename := filepath.Join(dir, name)
+		if *update {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filename, []byte(actual), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		golden, err := os.ReadFile(filename)
+		if err != nil {
+			t.Errorf("expect the golden file of %s, run `go test ./types -run Test_Snapshots -update` to create it: %v", resource.Url, err)
+			continue
+		}
+		if string(golden) != actual {
+			t.Errorf("expect the definition of %s to match %s, run `go test ./types -run Test_Snapshots -update` if the change is expected\nexpected:\n%s\nactual:\n%s", resource.Url, filename, golden, actual)
+		}
+	}
+
+	// the golden files of the removed resources are stale
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if expected[entry.Name()] {
+			continue
+		}
+		if *update {
+			_ = os.Remove(filepath.Join(dir, entry.Name()))
+			continue
+		}
+		t.Errorf("expect the golden file %s to match a resource, run `go test ./types -run Test_Snapshots -update` to remove it", entry.Name())
+	}
+}
@@ -2,61 +2,141 @@ package types
 
 import (
 	"fmt"
-	"log"
 	"regexp"
 )
 
 var _ TypeBase = &StringType{}
 
 type StringType struct {
-	Type      string   `json:"$type"`
-	MinLength *uint64  `json:"minLength"`
-	MaxLength *uint64  `json:"maxLength"`
-	Sensitive bool     `json:"sensitive"`
-	Pattern   string   `json:"pattern"`
-	Enum      []string `json:"enum"`
+	Type           string                  `json:"$type"`
+	MinLength      *uint64                 `json:"minLength"`
+	MaxLength      *uint64                 `json:"maxLength"`
+	Sensitive      bool                    `json:"sensitive"`
+	Pattern        string                  `json:"pattern"`
+	Format         string                  `json:"format"`
+	Enum           []string                `json:"enum"`
+	Default        *string                 `json:"default"`
+	DeprecatedEnum map[string]*Deprecation `json:"deprecatedEnum"`
 }
 
 func (s *StringType) Validate(body interface{}, path string) []error {
-	if body == nil {
+	return s.Diagnose(body, path, nil).Errors()
+}
+
+func (s *StringType) Diagnose(body interface{}, path string, options *ValidationOptions) Diagnostics {
+	if body == nil || IsUnknown(body) {
 		return nil
 	}
 	v, ok := body.(string)
 	if !ok {
-		return []error{ErrorMismatch(path, "string", fmt.Sprintf("%T", body))}
+		return errorDiagnostics(path, ErrorMismatch(path, "string", fmt.Sprintf("%T", body)))
 	}
-	if v == "" {
-		// unknown values will be converted to "", skip validation for now
-		// TODO: improve the validation to support unknown values
+	if v == "" && !options.orDefault().ValidateEmptyStrings {
 		return nil
 	}
+	diagnostics := make(Diagnostics, 0)
+	if s.DeprecatedEnum[v] != nil {
+		diagnostics = append(diagnostics, deprecationDiagnostic(path, s.DeprecatedEnum[v]))
+	}
+	if s.isEvolvableEnumMember(v) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity: SeverityInfo,
+			Path:     path,
+			Err:      InfoEvolvableEnumMember(path, v),
+		})
+	}
 	if s.MinLength != nil && uint64(len(v)) < *s.MinLength {
-		return []error{ErrorCommon(path, fmt.Sprintf("string length is less than %d", *s.MinLength))}
+		return append(diagnostics, errorDiagnostics(path, ErrorCommon(path, fmt.Sprintf("string length is less than %d", *s.MinLength)))...)
 	}
 	if s.MaxLength != nil && uint64(len(v)) > *s.MaxLength {
-		return []error{ErrorCommon(path, fmt.Sprintf("string length is greater than %d", *s.MaxLength))}
+		return append(diagnostics, errorDiagnostics(path, ErrorCommon(path, fmt.Sprintf("string length is greater than %d", *s.MaxLength)))...)
 	}
 	if s.Pattern != "" {
 		isMatch, err := regexp.Match(s.Pattern, []byte(v))
 		if err != nil {
-			log.Printf("[WARN] failed to match pattern %s: %s", s.Pattern, err)
-			return nil
+			return append(diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				Path:     path,
+				Err:      WarningInvalidPattern(path, s.Pattern, err),
+			})
 		}
 		if !isMatch {
-			return []error{ErrorCommon(path, fmt.Sprintf("string does not match pattern %s", s.Pattern))}
+			return append(diagnostics, errorDiagnostics(path, ErrorCommon(path, fmt.Sprintf("string does not match pattern %s", s.Pattern)))...)
+		}
+	}
+	return diagnostics
+}
+
+// isEvolvableEnumMember returns true if the value is defined after the `unknownFutureValue` sentinel member of an
+// evolvable enum, such members are only returned by the service when the client opts in.
+func (s *StringType) isEvolvableEnumMember(value string) bool {
+	sentinel := false
+	for _, member := range s.Enum {
+		if member == "unknownFutureValue" {
+			sentinel = true
+			continue
+		}
+		if sentinel && member == value {
+			return true
 		}
 	}
-	return nil
+	return false
 }
 
 func (s *StringType) FilterReadOnlyFields(i interface{}) interface{} {
 	return i
 }
 
+func (s *StringType) ApplyDefaults(i interface{}) interface{} {
+	return i
+}
+
 func (s *StringType) FilterConfigurableFields(i interface{}) interface{} {
 	return i
 }
 
+func (s *StringType) FilterWriteOnlyFields(i interface{}) interface{} {
+	return i
+}
+
+func (s *StringType) MergeWriteOnlyFields(state interface{}, config interface{}) interface{} {
+	return state
+}
+
+func (s *StringType) Redact(i interface{}) interface{} {
+	if s == nil || i == nil || !s.Sensitive {
+		return i
+	}
+	// only the strings are redacted, e.g. the objects are kept when the string is an element of a union
+	if _, ok := i.(string); !ok {
+		return i
+	}
+	return RedactedValue
+}
+
+func (s *StringType) Sample(options *SampleOptions) interface{} {
+	options = options.orDefault()
+	if s.Default != nil {
+		return *s.Default
+	}
+	if len(s.Enum) != 0 {
+		values := make([]string, 0)
+		for _, value := range s.Enum {
+			if s.DeprecatedEnum[value] == nil && value != "unknownFutureValue" && !s.isEvolvableEnumMember(value) {
+				values = append(values, value)
+			}
+		}
+		if len(values) == 0 {
+			values = s.Enum
+		}
+		return values[options.intn(len(values))]
+	}
+	if value, ok := sampleString(s.Pattern, s.MinLength, s.MaxLength, s.Format, options); ok {
+		return value
+	}
+	return ""
+}
+
 func (s *StringType) AsTypeBase() *TypeBase {
 	typeBase := TypeBase(s)
 	return &typeBase
@@ -16,7 +16,69 @@ type TypeBase interface {
 	Validate(interface{}, string) []error
 }
 
+// The functions below are implemented by the types of this package, the other implementations of TypeBase could
+// implement them as the methods of the same signatures, otherwise the fallbacks are used.
+
+// Diagnose validates the body and reports the errors, and also the warnings like the usages of deprecated elements.
+// It falls back to the errors of Validate.
+func Diagnose(t TypeBase, body interface{}, path string, options *ValidationOptions) Diagnostics {
+	switch v := t.(type) {
+	case nil:
+		return nil
+	case interface {
+		Diagnose(interface{}, string, *ValidationOptions) Diagnostics
+	}:
+		return v.Diagnose(body, path, options)
+	}
+	return errorDiagnostics(path, t.Validate(body, path)...)
+}
+
+// ApplyDefaults returns a copy of the body whose missing properties are filled with the defaults of the schema.
+// It falls back to the body.
+func ApplyDefaults(t TypeBase, body interface{}) interface{} {
+	if v, ok := t.(interface{ ApplyDefaults(interface{}) interface{} }); ok {
+		return v.ApplyDefaults(body)
+	}
+	return body
+}
+
+// FilterWriteOnlyFields removes the write-only fields, which are never returned by the service, from the body.
+// It falls back to the body.
+func FilterWriteOnlyFields(t TypeBase, body interface{}) interface{} {
+	if v, ok := t.(interface{ FilterWriteOnlyFields(interface{}) interface{} }); ok {
+		return v.FilterWriteOnlyFields(body)
+	}
+	return body
+}
+
+// MergeWriteOnlyFields copies the write-only fields of the config to the state read from the service.
+// It falls back to the state.
+func MergeWriteOnlyFields(t TypeBase, state interface{}, config interface{}) interface{} {
+	if v, ok := t.(interface {
+		MergeWriteOnlyFields(interface{}, interface{}) interface{}
+	}); ok {
+		return v.MergeWriteOnlyFields(state, config)
+	}
+	return state
+}
+
+// Redact returns a copy of the body whose sensitive values are replaced by RedactedValue. It falls back to the body.
+func Redact(t TypeBase, body interface{}) interface{} {
+	if v, ok := t.(interface{ Redact(interface{}) interface{} }); ok {
+		return v.Redact(body)
+	}
+	return body
+}
+
+// NewTypeBaseFromOpenAPISchema converts the schema to a type, the properties are detected as sensitive by
+// DefaultSensitivePropertyRules.
 func NewTypeBaseFromOpenAPISchema(input *openapi3.Schema, cache map[*openapi3.Schema]*TypeBase) *TypeBase {
+	marker := &sensitiveMarker{rules: DefaultSensitivePropertyRules()}
+	defer marker.finish()
+	return newTypeBaseFromOpenAPISchema(input, cache, marker)
+}
+
+func newTypeBaseFromOpenAPISchema(input *openapi3.Schema, cache map[*openapi3.Schema]*TypeBase, marker *sensitiveMarker) *TypeBase {
 	if input == nil {
 		return nil
 	}
@@ -31,36 +93,7 @@ func NewTypeBaseFromOpenAPISchema(input *openapi3.Schema, cache map[*openapi3.Sc
 	}
 
 	if len(input.AllOf) != 0 {
-		objectType := &ObjectType{
-			Type:                 "object",
-			Properties:           map[string]ObjectProperty{},
-			AdditionalProperties: nil,
-			Sensitive:            false,
-		}
-		cache[input] = objectType.AsTypeBase()
-
-		objectTypeList := make([]*ObjectType, 0)
-		for _, schema := range input.AllOf {
-			if schema.Value == nil {
-				log.Printf("[WARN] schema.Value is nil")
-				continue
-			}
-			childObjectType := NewTypeBaseFromOpenAPISchema(schema.Value, cache)
-			if childObjectType == nil {
-				log.Printf("[WARN] objectType is nil")
-				continue
-			}
-
-			objectTypeList = append(objectTypeList, (*childObjectType).(*ObjectType))
-		}
-
-		// combine all object types into one
-		for _, objType := range objectTypeList {
-			for key, value := range objType.Properties {
-				objectType.Properties[key] = value
-			}
-		}
-		return objectType.AsTypeBase()
+		return newAllOfType(input, cache, marker)
 	}
 
 	if len(input.AnyOf) != 0 && input.Discriminator == nil {
@@ -75,7 +108,7 @@ func NewTypeBaseFromOpenAPISchema(input *openapi3.Schema, cache map[*openapi3.Sc
 				log.Printf("[WARN] schema.Value is nil")
 				continue
 			}
-			element := NewTypeBaseFromOpenAPISchema(schema.Value, cache)
+			element := newTypeBaseFromOpenAPISchema(schema.Value, cache, marker)
 			if element == nil {
 				log.Printf("[WARN] element is nil")
 				continue
@@ -101,7 +134,7 @@ func NewTypeBaseFromOpenAPISchema(input *openapi3.Schema, cache map[*openapi3.Sc
 				log.Printf("[WARN] schema.Value is nil")
 				continue
 			}
-			element := NewTypeBaseFromOpenAPISchema(schema.Value, cache)
+			element := newTypeBaseFromOpenAPISchema(schema.Value, cache, marker)
 			if element == nil {
 				log.Printf("[WARN] element is nil")
 				continue
@@ -120,11 +153,14 @@ func NewTypeBaseFromOpenAPISchema(input *openapi3.Schema, cache map[*openapi3.Sc
 		t := ObjectType{
 			Type:                 "object",
 			Name:                 input.Title,
-			AdditionalProperties: nil, //TODO
-			Sensitive:            false,
+			AdditionalProperties: nil,
+			Sensitive:            isSensitiveSchema(input),
+			Default:              defaultObject(input.Default),
 		}
 		cache[input] = t.AsTypeBase()
 
+		t.AdditionalProperties = newAdditionalPropertiesTypeReference(input.AdditionalProperties, cache, marker)
+
 		properties := make(map[string]ObjectProperty)
 
 		requiredSet := make(map[string]bool)
@@ -138,7 +174,7 @@ func NewTypeBaseFromOpenAPISchema(input *openapi3.Schema, cache map[*openapi3.Sc
 				continue
 			}
 
-			valueType := NewTypeBaseFromOpenAPISchema(value.Value, cache)
+			valueType := newTypeBaseFromOpenAPISchema(value.Value, cache, marker)
 			if valueType == nil {
 				log.Printf("[WARN] valueType is nil")
 				continue
@@ -154,13 +190,23 @@ func NewTypeBaseFromOpenAPISchema(input *openapi3.Schema, cache map[*openapi3.Sc
 			if value.Value.WriteOnly {
 				flags = append(flags, WriteOnly)
 			}
+			if isEntityKeyProperty(input, key) {
+				flags = append(flags, Identifier)
+			}
+			if isNavigationProperty, ok := value.Value.Extensions["x-ms-navigationProperty"].(bool); ok && isNavigationProperty {
+				flags = append(flags, Navigation)
+			}
+
+			propertyType := &TypeReference{
+				Type: *valueType,
+			}
+			marker.markProperty(key, value.Value, propertyType)
 
 			objectProperty := ObjectProperty{
-				Type: &TypeReference{
-					Type: *valueType,
-				},
+				Type:        propertyType,
 				Flags:       flags,
 				Description: &value.Value.Description,
+				Deprecated:  newDeprecation(value.Value.Deprecated, value.Value.Extensions),
 			}
 			properties[key] = objectProperty
 		}
@@ -169,11 +215,14 @@ func NewTypeBaseFromOpenAPISchema(input *openapi3.Schema, cache map[*openapi3.Sc
 		return t.AsTypeBase()
 	case input.Type.Is("string"):
 		t := StringType{
-			Type:      "string",
-			MinLength: &input.MinLength,
-			MaxLength: input.MaxLength,
-			Sensitive: false,
-			Pattern:   input.Pattern,
+			Type:           "string",
+			MinLength:      &input.MinLength,
+			MaxLength:      input.MaxLength,
+			Sensitive:      isSensitiveSchema(input),
+			Pattern:        input.Pattern,
+			Format:         input.Format,
+			Default:        defaultString(input.Default),
+			DeprecatedEnum: newEnumDeprecations(input.Extensions),
 		}
 		if input.Enum != nil {
 			t.Enum = make([]string, 0)
@@ -185,7 +234,8 @@ func NewTypeBaseFromOpenAPISchema(input *openapi3.Schema, cache map[*openapi3.Sc
 		return t.AsTypeBase()
 	case input.Type.Is("boolean"):
 		t := BooleanType{
-			Type: "boolean",
+			Type:    "boolean",
+			Default: defaultBoolean(input.Default),
 		}
 		cache[input] = t.AsTypeBase()
 		return t.AsTypeBase()
@@ -194,12 +244,13 @@ func NewTypeBaseFromOpenAPISchema(input *openapi3.Schema, cache map[*openapi3.Sc
 			Type:      "array",
 			MinLength: &input.MinItems,
 			MaxLength: input.MaxItems,
+			Default:   defaultArray(input.Default),
 		}
 		cache[input] = t.AsTypeBase()
 
 		var itemType *TypeBase
 		if input.Items != nil {
-			itemType = NewTypeBaseFromOpenAPISchema(input.Items.Value, cache)
+			itemType = newTypeBaseFromOpenAPISchema(input.Items.Value, cache, marker)
 		} else {
 			log.Printf("[WARN] array item is nil")
 		}
@@ -214,6 +265,7 @@ func NewTypeBaseFromOpenAPISchema(input *openapi3.Schema, cache map[*openapi3.Sc
 			Format:   input.Format,
 			MinValue: input.Min,
 			MaxValue: input.Max,
+			Default:  defaultNumber(input.Default),
 		}
 		cache[input] = t.AsTypeBase()
 		return t.AsTypeBase()
@@ -235,3 +287,223 @@ func NewTypeBaseFromOpenAPISchema(input *openapi3.Schema, cache map[*openapi3.Sc
 	cache[input] = t.AsTypeBase()
 	return t.AsTypeBase()
 }
+
+// newAllOfType merges the members of allOf into one object type, the properties of the later members override the
+// earlier ones, e.g. the derived types override the `@odata.type` of the base types, and the flags and the required
+// sets are combined. The properties defined alongside allOf are merged last.
+func newAllOfType(input *openapi3.Schema, cache map[*openapi3.Schema]*TypeBase, marker *sensitiveMarker) *TypeBase {
+	objectType := &ObjectType{
+		Type:                 "object",
+		Name:                 allOfTitle(input),
+		BaseTypes:            baseTypeNames(input, map[*openapi3.Schema]bool{}),
+		Properties:           map[string]ObjectProperty{},
+		AdditionalProperties: nil,
+		Sensitive:            isSensitiveSchema(input),
+		Default:              defaultObject(input.Default),
+	}
+	cache[input] = objectType.AsTypeBase()
+
+	members := make([]*openapi3.Schema, 0)
+	for _, schema := range input.AllOf {
+		if schema == nil || schema.Value == nil {
+			log.Printf("[WARN] schema.Value is nil")
+			continue
+		}
+		members = append(members, schema.Value)
+	}
+	if own := ownObjectSchema(input); own != nil {
+		members = append(members, own)
+	}
+
+	requiredSet := make(map[string]bool)
+	for _, required := range input.Required {
+		requiredSet[required] = true
+	}
+
+	var otherType *TypeBase
+	objectCount := 0
+	for _, member := range members {
+		for _, required := range member.Required {
+			requiredSet[required] = true
+		}
+		memberType := newTypeBaseFromOpenAPISchema(member, cache, marker)
+		if memberType == nil {
+			log.Printf("[WARN] objectType is nil")
+			continue
+		}
+		memberObjectType, ok := (*memberType).(*ObjectType)
+		if !ok {
+			// the members which aren't objects, e.g. a union or an enum wrapped by allOf, can't be merged
+			otherType = memberType
+			continue
+		}
+		objectCount++
+		if objectType.BaseType == nil && len(objectType.BaseTypes) != 0 && isBaseTypeMember(input, member, objectType.BaseTypes[0]) {
+			objectType.BaseType = &TypeReference{
+				Type: memberObjectType,
+			}
+		}
+		for key, value := range memberObjectType.Properties {
+			objectType.Properties[key] = mergeObjectProperty(objectType.Properties[key], value)
+		}
+		if memberObjectType.AdditionalProperties != nil {
+			objectType.AdditionalProperties = memberObjectType.AdditionalProperties
+		}
+		if memberObjectType.Sensitive {
+			objectType.Sensitive = true
+		}
+		if memberObjectType.Default != nil {
+			if objectType.Default == nil {
+				objectType.Default = make(map[string]interface{})
+			}
+			for key, value := range memberObjectType.Default {
+				if _, ok := objectType.Default[key]; !ok {
+					objectType.Default[key] = value
+				}
+			}
+		}
+	}
+
+	// allOf is only used to wrap the type when there are no object members, e.g. a reference with a description
+	if objectCount == 0 && otherType != nil {
+		cache[input] = otherType
+		return otherType
+	}
+	if otherType != nil {
+		log.Printf("[WARN] allOf member which isn't an object is ignored: %s", objectType.Name)
+	}
+
+	for key := range requiredSet {
+		if property, ok := objectType.Properties[key]; ok {
+			property.Flags = mergeObjectPropertyFlags(property.Flags, []ObjectPropertyFlag{Required})
+			objectType.Properties[key] = property
+		}
+	}
+	return objectType.AsTypeBase()
+}
+
+// isBaseTypeMember returns true if the member is the reference of the base type in allOf.
+func isBaseTypeMember(input *openapi3.Schema, member *openapi3.Schema, baseType string) bool {
+	for _, schema := range input.AllOf {
+		if schema != nil && schema.Value == member && schemaName(schema) == baseType {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeObjectProperty returns the property which is defined by both the base and the derived types, the type of the
+// derived type is used and the flags are combined.
+func mergeObjectProperty(base ObjectProperty, derived ObjectProperty) ObjectProperty {
+	out := derived
+	out.Flags = mergeObjectPropertyFlags(base.Flags, derived.Flags)
+	if out.Type == nil {
+		out.Type = base.Type
+	}
+	if (out.Description == nil || *out.Description == "") && base.Description != nil {
+		out.Description = base.Description
+	}
+	if out.Deprecated == nil {
+		out.Deprecated = base.Deprecated
+	}
+	return out
+}
+
+// mergeObjectPropertyFlags returns a new slice which contains the flags of both inputs, it's ordered by
+// PossibleObjectPropertyFlagValues.
+func mergeObjectPropertyFlags(a, b []ObjectPropertyFlag) []ObjectPropertyFlag {
+	var merged ObjectPropertyFlag
+	for _, flag := range append(append([]ObjectPropertyFlag{}, a...), b...) {
+		merged |= flag
+	}
+	out := make([]ObjectPropertyFlag, 0)
+	for _, flag := range PossibleObjectPropertyFlagValues() {
+		if flag != None && merged&flag != 0 {
+			out = append(out, flag)
+		}
+	}
+	return out
+}
+
+// ownObjectSchema returns the object schema of the properties which are defined alongside allOf, it returns nil if
+// there are no such properties.
+func ownObjectSchema(input *openapi3.Schema) *openapi3.Schema {
+	if len(input.Properties) == 0 && len(input.Required) == 0 && input.AdditionalProperties.Schema == nil && input.AdditionalProperties.Has == nil {
+		return nil
+	}
+	own := &openapi3.Schema{
+		Type:                 &openapi3.Types{openapi3.TypeObject},
+		Title:                input.Title,
+		Properties:           input.Properties,
+		Required:             input.Required,
+		AdditionalProperties: input.AdditionalProperties,
+	}
+	return own
+}
+
+// allOfTitle returns the title of the allOf schema, the title is defined by the last inline member in the documents,
+// e.g. `user` of `microsoft.graph.user`.
+func allOfTitle(input *openapi3.Schema) string {
+	if input.Title != "" {
+		return input.Title
+	}
+	for i := len(input.AllOf) - 1; i >= 0; i-- {
+		member := input.AllOf[i]
+		if member != nil && member.Ref == "" && member.Value != nil && member.Value.Title != "" {
+			return member.Value.Title
+		}
+	}
+	return ""
+}
+
+// baseTypeNames returns the fully-qualified names of the base types, the nearest base type is the first, e.g.
+// `microsoft.graph.directoryObject` and `microsoft.graph.entity` of `microsoft.graph.user`.
+func baseTypeNames(input *openapi3.Schema, visited map[*openapi3.Schema]bool) []string {
+	if input == nil || visited[input] {
+		return nil
+	}
+	visited[input] = true
+	var out []string
+	for _, member := range input.AllOf {
+		if member == nil || member.Value == nil {
+			continue
+		}
+		if name := schemaName(member); name != "" {
+			out = append(out, name)
+		}
+		out = append(out, baseTypeNames(member.Value, visited)...)
+	}
+	return out
+}
+
+func newAdditionalPropertiesTypeReference(input openapi3.AdditionalProperties, cache map[*openapi3.Schema]*TypeBase, marker *sensitiveMarker) *TypeReference {
+	if input.Schema != nil {
+		if input.Schema.Value == nil {
+			log.Printf("[WARN] additionalProperties schema.Value is nil")
+			return nil
+		}
+		valueType := newTypeBaseFromOpenAPISchema(input.Schema.Value, cache, marker)
+		if valueType == nil {
+			log.Printf("[WARN] additionalProperties valueType is nil")
+			return nil
+		}
+		return &TypeReference{
+			Type: *valueType,
+		}
+	}
+	if input.Has != nil && *input.Has {
+		t := AnyType{
+			Type: "any",
+		}
+		return &TypeReference{
+			Type: *t.AsTypeBase(),
+		}
+	}
+	return nil
+}
+
+// isEntityKeyProperty returns true if the property is the key of the entity types, all entity types are derived from
+// `microsoft.graph.entity` whose key is `id`.
+func isEntityKeyProperty(input *openapi3.Schema, key string) bool {
+	return input != nil && input.Title == "entity" && key == "id"
+}
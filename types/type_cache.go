@@ -0,0 +1,127 @@
+package types
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// typeCache caches the types converted from the schemas of an api version, it's safe for concurrent use.
+// It's shared by the definitions and the reference resolvers of the api version, and it keeps at most one type for
+// each schema. If the capacity is specified, the least recently used types are evicted when a conversion finishes
+// with more types than the capacity, the evicted types are still valid but they're converted again on the next use.
+type typeCache struct {
+	mutex     sync.Mutex
+	types     map[*openapi3.Schema]*TypeBase
+	hits      uint64
+	misses    uint64
+	evictions uint64
+	// rules detect the sensitive properties of the converted types
+	rules []SensitivePropertyRule
+	// capacity is the maximum number of the converted types, it's unbounded if it's 0
+	capacity int
+	// recent are the schemas of the converted types, the most recently used first, it's only kept if the capacity is
+	// specified
+	recent   *list.List
+	elements map[*openapi3.Schema]*list.Element
+}
+
+// CacheStats describes the conversion cache of an api version.
+type CacheStats struct {
+	APIVersion string
+	// Entries is the number of converted schemas.
+	Entries int
+	// Hits is the number of conversions served by the cache.
+	Hits uint64
+	// Misses is the number of conversions which are not cached.
+	Misses uint64
+	// Evictions is the number of converted types evicted by WithMaxConvertedTypes.
+	Evictions uint64
+}
+
+func newTypeCache(rules []SensitivePropertyRule, capacity int) *typeCache {
+	return &typeCache{
+		types:    make(map[*openapi3.Schema]*TypeBase),
+		rules:    rules,
+		capacity: capacity,
+		recent:   list.New(),
+		elements: make(map[*openapi3.Schema]*list.Element),
+	}
+}
+
+// convert converts the schema to a type, the types of the same schema are shared.
+func (c *typeCache) convert(input *openapi3.Schema) *TypeBase {
+	if input == nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.types[input] != nil {
+		c.hits++
+		c.touch(input)
+		return c.types[input]
+	}
+	c.misses++
+	marker := &sensitiveMarker{rules: c.rules}
+	out := newTypeBaseFromOpenAPISchema(input, c.types, marker)
+	marker.finish()
+	c.evict(input)
+	return out
+}
+
+// touch marks the type of the schema as the most recently used.
+func (c *typeCache) touch(input *openapi3.Schema) {
+	if c.capacity <= 0 {
+		return
+	}
+	if element, ok := c.elements[input]; ok {
+		c.recent.MoveToFront(element)
+	}
+}
+
+// evict tracks the types added by the conversion of the schema, and evicts the least recently used types which exceed
+// the capacity. The types are only evicted when the conversion finishes, because the recursive types are resolved by
+// the cache during the conversion.
+func (c *typeCache) evict(input *openapi3.Schema) {
+	if c.capacity <= 0 {
+		return
+	}
+	for schema := range c.types {
+		if _, ok := c.elements[schema]; !ok {
+			c.elements[schema] = c.recent.PushFront(schema)
+		}
+	}
+	c.touch(input)
+	for c.recent.Len() > c.capacity {
+		oldest := c.recent.Back()
+		schema := oldest.Value.(*openapi3.Schema)
+		c.recent.Remove(oldest)
+		delete(c.elements, schema)
+		delete(c.types, schema)
+		c.evictions++
+	}
+}
+
+func (c *typeCache) reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.types = make(map[*openapi3.Schema]*TypeBase)
+	c.recent.Init()
+	c.elements = make(map[*openapi3.Schema]*list.Element)
+	c.hits = 0
+	c.misses = 0
+	c.evictions = 0
+}
+
+func (c *typeCache) stats(apiVersion string) CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return CacheStats{
+		APIVersion: apiVersion,
+		Entries:    len(c.types),
+		Hits:       c.hits,
+		Misses:     c.misses,
+		Evictions:  c.evictions,
+	}
+}
@@ -0,0 +1,168 @@
+package types
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type TypeKind string
+
+const (
+	// TypeKindEntity is an entity type which is addressable by its `id`, e.g. `microsoft.graph.application`.
+	TypeKindEntity TypeKind = "entity"
+
+	// TypeKindComplex is a complex type which is embedded in other types, e.g. `microsoft.graph.passwordCredential`.
+	TypeKindComplex TypeKind = "complex"
+)
+
+const entityTypeSchemaName = "microsoft.graph.entity"
+
+// TypeDefinition describes an entity type or a complex type defined in the components of the schema.
+type TypeDefinition struct {
+	// Name is the fully-qualified name, e.g. `microsoft.graph.application`.
+	Name        string
+	Kind        TypeKind
+	Description string
+	// BaseType is the fully-qualified name of the nearest base type, it's empty if the type isn't derived.
+	BaseType string
+}
+
+// ListTypeDefinitions returns the entity types and the complex types of the api version, sorted by name.
+func (r *MSGraphSchemaLoader) ListTypeDefinitions(apiVersion string) []TypeDefinition {
+	index := r.getSchemaIndex(apiVersion)
+	if index == nil {
+		return nil
+	}
+	return append([]TypeDefinition(nil), index.typeDefinitions...)
+}
+
+// GetTypeDefinition returns the type of the schema whose fully-qualified name is the name, e.g. `microsoft.graph.application`.
+func (r *MSGraphSchemaLoader) GetTypeDefinition(apiVersion, name string) *TypeBase {
+	key := definitionKey{apiVersion: apiVersion, typeName: name}
+	var generation uint64
+	if r.definitions != nil {
+		generation = r.definitions.currentGeneration()
+		if definition, ok := r.definitions.get(key).(*TypeBase); ok {
+			return definition
+		}
+	}
+
+	index := r.getSchemaIndex(apiVersion)
+	if index == nil {
+		return nil
+	}
+	schema := index.schemas[name]
+	if schema == nil || schema.Value == nil {
+		return nil
+	}
+	out := r.typeCache(apiVersion).convert(schema.Value)
+	if r.definitions != nil && out != nil {
+		r.definitions.add(key, out, generation)
+	}
+	return out
+}
+
+// ListDerivedTypes returns the types which are derived from the type directly or indirectly, sorted by name, e.g.
+// `microsoft.graph.user` and `microsoft.graph.group` of `microsoft.graph.directoryObject`, together with the type
+// itself they're the types which can appear in the collections of the type.
+func (r *MSGraphSchemaLoader) ListDerivedTypes(apiVersion, name string) []TypeDefinition {
+	index := r.getSchemaIndex(apiVersion)
+	if index == nil {
+		return nil
+	}
+	derived := make(map[string]bool)
+	for _, derivedName := range index.derivedTypes[name] {
+		derived[derivedName] = true
+	}
+	out := make([]TypeDefinition, 0)
+	for _, definition := range index.typeDefinitions {
+		if derived[definition.Name] {
+			out = append(out, definition)
+		}
+	}
+	return out
+}
+
+// ListEntityTypeResources returns the collections where the entities can be created or read, keyed by the
+// fully-qualified names of the entity types, including the nested collections like
+// `/applications/{application-id}/extensionProperties`. The collections of each entity type are sorted by URL.
+func (r *MSGraphSchemaLoader) ListEntityTypeResources(apiVersion string) map[string][]ResourceType {
+	index := r.getSchemaIndex(apiVersion)
+	if index == nil {
+		return nil
+	}
+
+	out := make(map[string][]ResourceType)
+	urls := make(map[string]bool)
+	for _, resource := range index.resources {
+		if resource.Kind != ResourceKindCollection || resource.EntityType == "" {
+			continue
+		}
+		out[resource.EntityType] = append(out[resource.EntityType], resource.copy())
+		urls[resource.Url] = true
+	}
+	for _, resource := range index.readableResources {
+		if resource.EntityType == "" || urls[resource.Url] {
+			continue
+		}
+		if item := index.paths[resource.Url]; item == nil || item.get == nil || !item.get.collection {
+			continue
+		}
+		out[resource.EntityType] = append(out[resource.EntityType], resource.copy())
+	}
+
+	for _, resources := range out {
+		sort.Slice(resources, func(i, j int) bool {
+			return resources[i].Url < resources[j].Url
+		})
+	}
+	return out
+}
+
+// typeKind returns the kind of the schema, it returns an empty string if the schema is neither an entity type nor a
+// complex type, e.g. the enums, the collection responses and the schemas without namespaces.
+func (s *schemaIndex) typeKind(name string) TypeKind {
+	schema := s.schemas[name]
+	if schema == nil || schema.Value == nil || !strings.Contains(name, ".") || strings.HasSuffix(name, "CollectionResponse") {
+		return ""
+	}
+	if s.isEntityType(name, make(map[string]bool)) {
+		return TypeKindEntity
+	}
+	if isObjectSchema(schema.Value) {
+		return TypeKindComplex
+	}
+	return ""
+}
+
+// isEntityType returns true if the schema is `microsoft.graph.entity` or it's derived from it.
+func (s *schemaIndex) isEntityType(name string, visited map[string]bool) bool {
+	if name == entityTypeSchemaName {
+		return true
+	}
+	schema := s.schemas[name]
+	if visited[name] || schema == nil || schema.Value == nil {
+		return false
+	}
+	visited[name] = true
+	for _, member := range schema.Value.AllOf {
+		if baseName := schemaName(member); baseName != "" && s.isEntityType(baseName, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+func isObjectSchema(input *openapi3.Schema) bool {
+	if input.Type.Is("object") || len(input.Properties) != 0 {
+		return true
+	}
+	for _, member := range input.AllOf {
+		if member != nil && member.Value != nil && isObjectSchema(member.Value) {
+			return true
+		}
+	}
+	return false
+}
@@ -8,26 +8,32 @@ type UnionType struct {
 }
 
 func (t *UnionType) Validate(body interface{}, path string) []error {
-	if t == nil || body == nil {
-		return []error{}
+	return t.Diagnose(body, path, nil).Errors()
+}
+
+func (t *UnionType) Diagnose(body interface{}, path string, options *ValidationOptions) Diagnostics {
+	if t == nil || body == nil || IsUnknown(body) {
+		return Diagnostics{}
 	}
-	errors := make([]error, 0)
-	valid := false
 	for _, element := range t.Elements {
 		if element.Type == nil {
 			continue
 		}
-		temp := element.Type.Validate(body, path)
-		if len(temp) == 0 {
-			valid = true
-			break
+		temp := Diagnose(element.Type, body, path, options)
+		if !temp.HasErrors() {
+			return temp
 		}
 	}
-	if !valid {
-		errors = append(errors, ErrorNotMatchAny(path))
+	return errorDiagnostics(path, ErrorNotMatchAny(path))
+}
+
+func (t *UnionType) ApplyDefaults(i interface{}) interface{} {
+	if element := t.matchElement(i); element != nil {
+		return ApplyDefaults(element, i)
 	}
-	return errors
+	return i
 }
+
 func (t *UnionType) FilterReadOnlyFields(i interface{}) interface{} {
 	return i
 }
@@ -36,7 +42,98 @@ func (t *UnionType) FilterConfigurableFields(i interface{}) interface{} {
 	return i
 }
 
+func (t *UnionType) FilterWriteOnlyFields(i interface{}) interface{} {
+	if element := t.matchElement(i); element != nil {
+		return FilterWriteOnlyFields(element, i)
+	}
+	return i
+}
+
+func (t *UnionType) MergeWriteOnlyFields(state interface{}, config interface{}) interface{} {
+	if element := t.matchElement(config); element != nil {
+		return MergeWriteOnlyFields(element, state, config)
+	}
+	return state
+}
+
+func (t *UnionType) Redact(i interface{}) interface{} {
+	if t == nil || i == nil {
+		return i
+	}
+	// the values are redacted by all elements, so that the sensitive values are masked whichever element the body matches
+	res := i
+	for _, element := range t.Elements {
+		if element.Type == nil {
+			continue
+		}
+		res = Redact(element.Type, res)
+	}
+	return res
+}
+
+func (t *UnionType) Sample(options *SampleOptions) interface{} {
+	options = options.orDefault()
+	if len(t.Elements) == 0 {
+		return nil
+	}
+	// the elements are tried from a random one, the first valid sample is returned
+	start := options.intn(len(t.Elements))
+	for i := range t.Elements {
+		element := t.Elements[(start+i)%len(t.Elements)]
+		if element == nil || element.Type == nil {
+			continue
+		}
+		value := Sample(element.Type, options)
+		if len(element.Type.Validate(value, "")) == 0 {
+			return value
+		}
+	}
+	return nil
+}
+
 func (t *UnionType) AsTypeBase() *TypeBase {
 	typeBase := TypeBase(t)
 	return &typeBase
 }
+
+// matchElement returns the first element that the body is valid against, if there's none, it returns the first element
+// of the same kind as the body.
+func (t *UnionType) matchElement(body interface{}) TypeBase {
+	if t == nil || body == nil {
+		return nil
+	}
+	for _, element := range t.Elements {
+		if element.Type != nil && !Diagnose(element.Type, body, "", nil).HasErrors() {
+			return element.Type
+		}
+	}
+	for _, element := range t.Elements {
+		if element.Type == nil {
+			continue
+		}
+		switch element.Type.(type) {
+		case *ObjectType:
+			if _, ok := body.(map[string]interface{}); ok {
+				return element.Type
+			}
+		case *ArrayType:
+			if _, ok := body.([]interface{}); ok {
+				return element.Type
+			}
+		case *StringType:
+			if _, ok := body.(string); ok {
+				return element.Type
+			}
+		case *BooleanType:
+			if _, ok := body.(bool); ok {
+				return element.Type
+			}
+		case *NumberType:
+			switch body.(type) {
+			case float64, float32, int64, int32, int:
+				return element.Type
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package types
+
+import "strings"
+
+type UnknownPropertyPolicy int
+
+const (
+	// UnknownPropertyError reports the properties which are not defined in the schema as errors.
+	UnknownPropertyError UnknownPropertyPolicy = iota
+
+	// UnknownPropertyWarning reports the properties which are not defined in the schema as warnings.
+	UnknownPropertyWarning
+
+	// UnknownPropertyIgnore doesn't report the properties which are not defined in the schema.
+	UnknownPropertyIgnore
+)
+
+// ValidationOptions controls the policies of the validation, so that different callers can choose how strict it is.
+// The zero value is the same as nil, which are the options used by Validate.
+type ValidationOptions struct {
+	// UnknownProperties controls how the properties which are not defined in the schema are reported.
+	UnknownProperties UnknownPropertyPolicy
+
+	// SkipRequired skips checking whether the required properties are specified, e.g. when validating a PATCH body.
+	SkipRequired bool
+
+	// SkipRequiredProperties are the paths of the required properties which are not checked, e.g. `name` or `web.homePageUrl`.
+	// It's `name` if it's nil, an empty slice checks all required properties.
+	SkipRequiredProperties []string
+
+	// ValidateEmptyStrings validates the empty strings, they're skipped by default for the callers which use empty
+	// strings as the placeholders of unknown values, the other callers should use UnknownValue instead.
+	ValidateEmptyStrings bool
+}
+
+// DefaultValidationOptions returns the options used by Validate.
+func DefaultValidationOptions() *ValidationOptions {
+	return &ValidationOptions{
+		UnknownProperties:      UnknownPropertyError,
+		SkipRequired:           false,
+		SkipRequiredProperties: []string{"name"},
+		ValidateEmptyStrings:   false,
+	}
+}
+
+func (o *ValidationOptions) orDefault() *ValidationOptions {
+	if o == nil {
+		return DefaultValidationOptions()
+	}
+	return o
+}
+
+func (o *ValidationOptions) isRequiredPropertySkipped(path string) bool {
+	if o.SkipRequired {
+		return true
+	}
+	skippedProperties := o.SkipRequiredProperties
+	if skippedProperties == nil {
+		skippedProperties = DefaultValidationOptions().SkipRequiredProperties
+	}
+	path = strings.TrimPrefix(path, ".")
+	for _, skipped := range skippedProperties {
+		if path == skipped {
+			return true
+		}
+	}
+	return false
+}
+
+type unknownValue struct{}
+
+// UnknownValue is the placeholder of a value which isn't known yet, e.g. a value computed by another resource.
+// It's accepted by all types and it counts as specified for the required properties.
+var UnknownValue interface{} = unknownValue{}
+
+// IsUnknown returns true if the value is UnknownValue.
+func IsUnknown(value interface{}) bool {
+	_, ok := value.(unknownValue)
+	return ok
+}